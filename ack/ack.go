@@ -0,0 +1,261 @@
+// Package ack provides a queue with acknowledged consumption, where dequeued items are only
+// removed for good once the consumer acknowledges them.
+package ack
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/deque"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrUnknownDelivery is returned when acknowledging a delivery that isn't in flight,
+// because it was already acknowledged or has been redelivered since.
+var ErrUnknownDelivery = errors.New("ack: unknown delivery")
+
+// ErrNacked is the failure recorded for deliveries that were negatively acknowledged without a cause.
+var ErrNacked = errors.New("ack: delivery nacked")
+
+// ErrVisibilityTimeout is the failure recorded for deliveries whose visibility timeout expired.
+var ErrVisibilityTimeout = errors.New("ack: visibility timeout expired")
+
+// Option configures a Queue.
+type Option func(*config)
+
+type config struct {
+	visibility  time.Duration
+	clock       clock.Clock
+	maxAttempts int
+}
+
+// WithVisibilityTimeout makes deliveries that aren't acknowledged within d available again,
+// as if they had been negatively acknowledged.
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.visibility = d
+	}
+}
+
+// WithMaxAttempts moves items that failed n deliveries to the dead-letter queue instead of
+// redelivering them again.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithClock makes the queue time visibility timeouts with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// Delivery is an item handed out to a consumer, identified by a delivery ID that must be
+// passed to Ack or Nack.
+type Delivery[T any] struct {
+	ID      uint64
+	Item    T
+	Attempt int // 1 for the first delivery of the item, 2 for the first redelivery, and so on
+}
+
+// Queue is a FIFO queue where dequeued items stay in flight until they are acknowledged.
+// Items that are negatively acknowledged go back to the front of the queue, so they are
+// redelivered before anything else and in their original order.
+type Queue[T any] struct {
+	mu       sync.Mutex
+	ready    *deque.Deque[*message[T]]
+	inFlight map[uint64]*flight[T]
+	nextID   uint64
+	dead     *DeadLetters[T]
+	config
+}
+
+type message[T any] struct {
+	item     T
+	attempts int
+}
+
+type flight[T any] struct {
+	message *message[T]
+	timer   clock.Timer // Nil without a visibility timeout
+	gen     uint64      // Incremented every time the timer is replaced, so stale ones can tell
+}
+
+// New creates a Queue of initial size n.
+func New[T any](n int, opts ...Option) *Queue[T] {
+	if n <= 0 {
+		return nil
+	}
+	q := &Queue[T]{
+		ready:    deque.New[*message[T]](n),
+		inFlight: make(map[uint64]*flight[T]),
+		config:   config{clock: clock.Real},
+	}
+	for _, opt := range opts {
+		opt(&q.config)
+	}
+	q.dead = &DeadLetters[T]{queue: q, letters: cirque.New[DeadLetter[T]](n)}
+	return q
+}
+
+// Enqueue adds items to the back of the queue.
+func (q *Queue[T]) Enqueue(items ...T) {
+	messages := make([]*message[T], len(items))
+	for i, item := range items {
+		messages[i] = &message[T]{item: item}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.ready.PushBack(messages...)
+}
+
+// Dequeue hands out a maximum of n items, which stay in flight until they are acknowledged.
+func (q *Queue[T]) Dequeue(n int) []Delivery[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []Delivery[T]
+	for len(result) < n {
+		m, ok := q.ready.PopFront()
+		if !ok {
+			break
+		}
+		m.attempts++
+		q.nextID++
+
+		f := &flight[T]{message: m}
+		if q.visibility > 0 {
+			q.arm(q.nextID, f, q.visibility)
+		}
+		q.inFlight[q.nextID] = f
+
+		result = append(result, Delivery[T]{ID: q.nextID, Item: m.item, Attempt: m.attempts})
+	}
+	return result
+}
+
+// Ack removes the item of a delivery from the queue for good.
+func (q *Queue[T]) Ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.land(id); !ok {
+		return ErrUnknownDelivery
+	}
+	return nil
+}
+
+// Nack puts the item of a delivery back at the front of the queue to be redelivered.
+func (q *Queue[T]) Nack(id uint64) error {
+	return q.Fail(id, ErrNacked)
+}
+
+// Fail is like Nack, but records err as the cause, which is kept if the item ends up in the
+// dead-letter queue.
+func (q *Queue[T]) Fail(id uint64, err error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	m, ok := q.land(id)
+	if !ok {
+		return ErrUnknownDelivery
+	}
+	q.retry(id, m, err)
+	return nil
+}
+
+// Extend gives the consumer of a delivery another d before its visibility timeout expires.
+func (q *Queue[T]) Extend(id uint64, d time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, ok := q.inFlight[id]
+	if !ok {
+		return ErrUnknownDelivery
+	}
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	q.arm(id, f, d)
+	return nil
+}
+
+// arm starts the visibility timer of a delivery, replacing any previous one.
+// Must be called with mu held.
+func (q *Queue[T]) arm(id uint64, f *flight[T], d time.Duration) {
+	f.gen++
+	gen := f.gen
+	f.timer = q.clock.AfterFunc(d, func() { q.expire(id, gen) })
+}
+
+// expire puts the item of a delivery back at the front of the queue once its visibility timeout
+// expires, unless gen shows that the timer was replaced since.
+func (q *Queue[T]) expire(id, gen uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if f, ok := q.inFlight[id]; !ok || f.gen != gen {
+		// Acknowledged or extended in the meantime
+		return
+	}
+	m, _ := q.land(id)
+	q.retry(id, m, ErrVisibilityTimeout)
+}
+
+// retry puts the item of a failed delivery back at the front of the queue, or moves it to the
+// dead-letter queue if it has run out of attempts.
+// Must be called with mu held.
+func (q *Queue[T]) retry(id uint64, m *message[T], err error) {
+	if q.maxAttempts > 0 && m.attempts >= q.maxAttempts {
+		q.dead.letters.Enqueue(DeadLetter[T]{
+			Item:     m.item,
+			Attempts: m.attempts,
+			Err:      err,
+			At:       q.clock.Now(),
+		})
+		log.Debugf("Dead-lettered delivery %d after %d attempts: %v", id, m.attempts, err)
+		return
+	}
+	q.ready.PushFront(m)
+
+	log.Debugf("Requeued delivery %d after %d attempts: %v", id, m.attempts, err)
+}
+
+// land takes a delivery out of flight and stops its visibility timer.
+// Must be called with mu held.
+func (q *Queue[T]) land(id uint64) (*message[T], bool) {
+	f, ok := q.inFlight[id]
+	if !ok {
+		return nil, false
+	}
+	delete(q.inFlight, id)
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	return f.message, true
+}
+
+// Len returns the number of items waiting to be delivered.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.ready.Len()
+}
+
+// InFlight returns the number of delivered items that haven't been acknowledged yet.
+func (q *Queue[T]) InFlight() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.inFlight)
+}
+
+// DeadLetters returns the dead-letter queue of items that ran out of attempts.
+func (q *Queue[T]) DeadLetters() *DeadLetters[T] {
+	return q.dead
+}
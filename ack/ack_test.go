@@ -0,0 +1,95 @@
+package ack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestAckNack(t *testing.T) {
+	q := New[string](2)
+	q.Enqueue("a", "b", "c")
+
+	d := q.Dequeue(2)
+	if len(d) != 2 || d[0].Item != "a" || d[1].Item != "b" || d[0].Attempt != 1 {
+		t.Fatalf("Unexpected deliveries %+v.", d)
+	}
+	if q.Len() != 1 || q.InFlight() != 2 {
+		t.Fatal("Expected the delivered items to be in flight.")
+	}
+
+	if err := q.Ack(d[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Ack(d[0].ID); err != ErrUnknownDelivery {
+		t.Fatal("Expected a second Ack to fail.")
+	}
+
+	// A nacked item is redelivered before the rest.
+	if err := q.Nack(d[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	redelivered := q.Dequeue(1)
+	if redelivered[0].Item != "b" || redelivered[0].Attempt != 2 {
+		t.Fatalf("Unexpected redelivery %+v.", redelivered[0])
+	}
+
+	// The old delivery ID doesn't refer to the redelivered item.
+	if err := q.Ack(d[1].ID); err != ErrUnknownDelivery {
+		t.Fatal("Expected Ack of a stale delivery to fail.")
+	}
+	if q.Ack(redelivered[0].ID) != nil || q.InFlight() != 0 {
+		t.Fatal("Expected the redelivery to be acknowledged.")
+	}
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	q := New[string](2, WithVisibilityTimeout(time.Second), WithClock(c))
+	q.Enqueue("a", "b", "c")
+
+	d := q.Dequeue(2)
+	if err := q.Ack(d[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Extend(d[1].ID, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	late := q.Dequeue(1)
+
+	// Only the delivery that wasn't extended times out.
+	c.Advance(2 * time.Second)
+	if q.InFlight() != 1 || q.Len() != 1 {
+		t.Fatal("Expected the unacknowledged delivery to be available again.")
+	}
+	if err := q.Ack(late[0].ID); err != ErrUnknownDelivery {
+		t.Fatal("Expected Ack of a timed out delivery to fail.")
+	}
+
+	c.Advance(2 * time.Second)
+	redelivered := q.Dequeue(2)
+	if len(redelivered) != 2 || redelivered[0].Item != "b" || redelivered[1].Item != "c" {
+		t.Fatalf("Unexpected redeliveries %+v.", redelivered)
+	}
+}
+
+func TestExtendAfterTimerFired(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	q := New[string](2, WithVisibilityTimeout(time.Second), WithClock(c))
+	q.Enqueue("a")
+
+	d := q.Dequeue(1)
+	if err := q.Extend(d[0].ID, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// The first timer may have fired already and be waiting for the lock held by Extend.
+	q.expire(d[0].ID, 1)
+
+	if q.InFlight() != 1 || q.Len() != 0 {
+		t.Fatal("Expected the extended delivery to stay in flight.")
+	}
+	if err := q.Ack(d[0].ID); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,50 @@
+package ack
+
+import (
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+)
+
+// DeadLetter is an item that ran out of delivery attempts, along with the cause of its last failure.
+type DeadLetter[T any] struct {
+	Item     T
+	Attempts int
+	Err      error     // Cause of the last failed delivery
+	At       time.Time // Time of the last failed delivery
+}
+
+// DeadLetters is the dead-letter queue of a Queue.
+type DeadLetters[T any] struct {
+	queue   *Queue[T]
+	letters *cirque.Cirque[DeadLetter[T]]
+}
+
+// Len returns the number of dead letters.
+func (d *DeadLetters[T]) Len() int {
+	return d.letters.Len()
+}
+
+// Snapshot returns all dead letters, oldest first, without removing them.
+func (d *DeadLetters[T]) Snapshot() []DeadLetter[T] {
+	return d.letters.Snapshot()
+}
+
+// Dequeue removes and returns a maximum of n dead letters.
+func (d *DeadLetters[T]) Dequeue(n int) []DeadLetter[T] {
+	return d.letters.Dequeue(n)
+}
+
+// Requeue moves a maximum of n dead letters back to the end of the queue with a fresh set of
+// attempts, and returns how many were moved.
+func (d *DeadLetters[T]) Requeue(n int) int {
+	letters := d.letters.Dequeue(n)
+
+	items := make([]T, len(letters))
+	for i, l := range letters {
+		items[i] = l.Item
+	}
+	d.queue.Enqueue(items...)
+
+	return len(letters)
+}
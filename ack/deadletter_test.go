@@ -0,0 +1,40 @@
+package ack
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDeadLetters(t *testing.T) {
+	q := New[string](2, WithMaxAttempts(2))
+	q.Enqueue("a", "b")
+
+	boom := errors.New("boom")
+	for attempt := 1; attempt <= 2; attempt++ {
+		d := q.Dequeue(1)
+		if len(d) != 1 || d[0].Item != "a" || d[0].Attempt != attempt {
+			t.Fatalf("Unexpected delivery %+v.", d)
+		}
+		if err := q.Fail(d[0].ID, boom); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dead := q.DeadLetters()
+	letters := dead.Snapshot()
+	if len(letters) != 1 || letters[0].Item != "a" || letters[0].Attempts != 2 || letters[0].Err != boom {
+		t.Fatalf("Unexpected dead letters %+v.", letters)
+	}
+	if q.Len() != 1 {
+		t.Fatal("Expected the other item to stay in the queue.")
+	}
+
+	// A requeued item goes to the back with a fresh set of attempts.
+	if dead.Requeue(5) != 1 || dead.Len() != 0 {
+		t.Fatal("Expected the dead letter to be requeued.")
+	}
+	d := q.Dequeue(2)
+	if len(d) != 2 || d[0].Item != "b" || d[1].Item != "a" || d[1].Attempt != 1 {
+		t.Fatalf("Unexpected deliveries %+v.", d)
+	}
+}
@@ -0,0 +1,32 @@
+package cirque
+
+import (
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// WithEnqueueTime makes the queue record when every item is enqueued, so that OldestAge
+// can tell how long the oldest item has been waiting.
+func WithEnqueueTime[T any]() Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.stamp = true
+	}
+}
+
+// OldestAge returns how long the item at the front of the queue has been waiting.
+// It returns false if the queue is empty, or if it doesn't record enqueue times.
+func (cq *Cirque[T]) OldestAge() (time.Duration, bool) {
+	cq.readMu.Lock()
+	defer cq.readMu.Unlock()
+
+	h := cq.getReaderHead()
+	if h == cq.getWriterHead() {
+		return 0, false
+	}
+	e, ok := h.Value.(expiring[T])
+	if !ok || e.enqueued.IsZero() {
+		return 0, false
+	}
+	return clock.Since(cq.clock, e.enqueued), true
+}
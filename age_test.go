@@ -0,0 +1,29 @@
+package cirque
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOldestAge(t *testing.T) {
+	cq := New[int](4, WithEnqueueTime[int]())
+	if _, ok := cq.OldestAge(); ok {
+		t.Fatal("Expected no age for an empty queue.")
+	}
+
+	cq.Enqueue(1)
+	time.Sleep(10 * time.Millisecond)
+	cq.Enqueue(2)
+
+	age, ok := cq.OldestAge()
+	if !ok || age < 10*time.Millisecond {
+		t.Fatalf("Unexpected age %v of the oldest item.", age)
+	}
+	if items := cq.Dequeue(2); items[0] != 1 || items[1] != 2 {
+		t.Fatal("Expected items to be unchanged by enqueue times.")
+	}
+
+	if _, ok := New[int](4).OldestAge(); ok {
+		t.Fatal("Expected no age without recording enqueue times.")
+	}
+}
@@ -0,0 +1,64 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrType is returned when enqueueing an item of the wrong type into an AnyQueue.
+var ErrType = errors.New("cirque: item of wrong type")
+
+// AnyQueue is a queue of items of any type, for code that can't be generic, like plugins that
+// only learn item types at runtime. Use Any to get one out of a Cirque.
+type AnyQueue interface {
+	// Enqueue adds items to the queue like EnqueueWait, if they are all of the item type of the queue.
+	// Otherwise it adds none of them and returns ErrType.
+	Enqueue(items ...any) error
+	// Dequeue returns a maximum of n items from the queue.
+	Dequeue(n int) []any
+	// Len returns the number of items in the queue.
+	Len() int
+	// Type returns the item type of the queue.
+	Type() reflect.Type
+}
+
+// Any returns an AnyQueue backed by cq, which checks the types of items at runtime.
+func Any[T any](cq *Cirque[T]) AnyQueue {
+	return anyQueue[T]{cq}
+}
+
+type anyQueue[T any] struct {
+	cq *Cirque[T]
+}
+
+func (q anyQueue[T]) Enqueue(items ...any) error {
+	typed := make([]T, len(items))
+	for i, item := range items {
+		v, ok := item.(T)
+		// Interface item types also take nil, as their zero value.
+		if !ok && (item != nil || q.Type().Kind() != reflect.Interface) {
+			return fmt.Errorf("%w: got %T, want %v", ErrType, item, q.Type())
+		}
+		typed[i] = v
+	}
+	return q.cq.EnqueueWait(context.Background(), typed...)
+}
+
+func (q anyQueue[T]) Dequeue(n int) []any {
+	items := q.cq.Dequeue(n)
+	result := make([]any, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result
+}
+
+func (q anyQueue[T]) Len() int {
+	return q.cq.Len()
+}
+
+func (q anyQueue[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
@@ -0,0 +1,38 @@
+package cirque
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnyQueue(t *testing.T) {
+	q := Any(New[int](4))
+
+	if err := q.Enqueue(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(3, "four"); !errors.Is(err, ErrType) {
+		t.Fatal("Expected an item of the wrong type to be refused.")
+	}
+	if q.Len() != 2 || q.Type() != reflect.TypeFor[int]() {
+		t.Fatal("Expected the whole batch with the wrong type to be refused.")
+	}
+	if items := q.Dequeue(10); len(items) != 2 || items[0] != 1 || items[1] != 2 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestAnyQueueInterface(t *testing.T) {
+	q := Any(New[error](4))
+
+	if err := q.Enqueue(ErrFull, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(1); !errors.Is(err, ErrType) {
+		t.Fatal("Expected an item not implementing the interface to be refused.")
+	}
+	if items := q.Dequeue(10); len(items) != 2 || items[0] != ErrFull || items[1] != nil {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
@@ -0,0 +1,240 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrFull is returned by TryEnqueue when a batch doesn't fit in a bounded queue right now.
+var ErrFull = errors.New("cirque: queue full")
+
+// ErrTooLarge is returned when a batch can never fit in a bounded queue, even an empty one.
+var ErrTooLarge = errors.New("cirque: batch larger than queue bound")
+
+// WithWeightLimit limits the queue to items whose weights, as returned by weight, add up to at most
+// max. This suits items that stand for units of work of very different sizes. Batches are enqueued
+// all-or-nothing: either every item fits, possibly after waiting for readers, or none is written.
+// The underlying ring still starts at the size given to New and grows as needed.
+func WithWeightLimit[T any](max int64, weight func(T) int64) Option[T] {
+	return func(cq *Cirque[T]) {
+		if max <= 0 {
+			return
+		}
+		cq.bound.limit = max
+		cq.bound.cost = weight
+		cq.bound.freed = make(chan struct{})
+	}
+}
+
+// WithBound limits the queue to n items, like WithWeightLimit with a weight of 1 per item.
+func WithBound[T any](n int) Option[T] {
+	return WithWeightLimit(int64(n), func(T) int64 { return 1 })
+}
+
+// WithByteBudget limits the queue to items whose sizes, as returned by sizer, add up to at most
+// max bytes, like WithWeightLimit with the size as weight. This bounds the memory held by the
+// queue even when item sizes vary widely.
+func WithByteBudget[T any](max int64, sizer func(T) int64) Option[T] {
+	limit := WithWeightLimit(max, sizer)
+	return func(cq *Cirque[T]) {
+		limit(cq)
+		cq.sizer = sizer
+	}
+}
+
+// WithSpinWait makes writers waiting for room in a bounded queue retry spins times in a busy loop,
+// then yields more times letting other goroutines run, before parking until readers free up room.
+// Parking costs a few microseconds to wake up from, which spinning avoids on latency-critical
+// producers when readers are expected to free up room shortly, at the cost of burning CPU.
+func WithSpinWait[T any](spins, yields int) Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.bound.spins = max(spins, 0)
+		cq.bound.yields = max(yields, 0)
+	}
+}
+
+// WithBurst lets writers of a bounded queue that have waited for room for longer than after
+// go past the limit, up to a total weight of max. The queue stays bounded by its limit as usual,
+// while rare bursts get an escape valve instead of blocking producers indefinitely.
+// Once a burst passes, writers wait for the queue to drain below its limit again.
+func WithBurst[T any](after time.Duration, max int64) Option[T] {
+	return func(cq *Cirque[T]) {
+		if after > 0 {
+			cq.bound.burstAfter = after
+			cq.bound.burstLimit = max
+		}
+	}
+}
+
+// Weight returns the total weight of the items in a bounded queue and its limit,
+// or zeros for an unbounded queue. During a burst the weight may exceed the limit.
+func (cq *Cirque[T]) Weight() (used, limit int64) {
+	cq.bound.mu.Lock()
+	defer cq.bound.mu.Unlock()
+	return cq.bound.used, cq.bound.limit
+}
+
+// bound keeps track of how much of the limit of a bounded queue is in use.
+type bound[T any] struct {
+	limit  int64         // Maximum total cost of the items in the queue, or 0 if unbounded
+	cost   func(T) int64 // Cost of an item against the limit
+	spins  int           // Attempts to make in a busy loop before yielding
+	yields int           // Attempts to make yielding to other goroutines before parking
+
+	burstAfter time.Duration // How long a writer waits before it may go past the limit, or 0 if never
+	burstLimit int64         // Maximum total cost of the items in the queue during a burst
+
+	mu    sync.Mutex
+	used  int64         // Total cost of the items in the queue, plus that of batches being written
+	freed chan struct{} // Closed and replaced whenever items leave the queue
+}
+
+// Largest total cost that writers can ever reach.
+func (b *bound[T]) ceiling() int64 {
+	if b.burstAfter > 0 {
+		return max(b.limit, b.burstLimit)
+	}
+	return b.limit
+}
+
+// Take cost out of limit if it fits, or return a channel that is closed once something is freed.
+func (b *bound[T]) acquire(cost, limit int64) (bool, <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.used+cost > limit {
+		return false, b.freed
+	}
+	b.used += cost
+	return true, nil
+}
+
+// Take cost out of the limit like acquire, retrying as configured before giving up
+// and returning a channel to park on.
+func (b *bound[T]) acquireSpinning(cost, limit int64) (bool, <-chan struct{}) {
+	for i := 0; i < b.spins+b.yields; i++ {
+		if ok, _ := b.acquire(cost, limit); ok {
+			return true, nil
+		}
+		if i >= b.spins {
+			runtime.Gosched()
+		}
+	}
+	return b.acquire(cost, limit)
+}
+
+// Give cost back to the limit, waking up waiting writers.
+func (b *bound[T]) release(cost int64) {
+	if cost == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.used -= cost
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+// Total cost of elements.
+func (b *bound[T]) costOf(elements []T) int64 {
+	var total int64
+	for _, item := range elements {
+		total += b.cost(item)
+	}
+	return total
+}
+
+// TryEnqueue adds the input elements to a bounded queue if they all fit right now.
+// Otherwise it writes none of them and returns ErrFull, or ErrTooLarge if they never can.
+// On an unbounded queue it is the same as Enqueue. On a closed queue it returns ErrClosed.
+func (cq *Cirque[T]) TryEnqueue(elements ...T) error {
+	if cq.bound.limit == 0 {
+		_, err := cq.enqueue(elements, nil, time.Time{})
+		return err
+	}
+
+	if cq.isClosed() {
+		return ErrClosed
+	}
+	cost := cq.bound.costOf(elements)
+	if cost > cq.bound.limit {
+		return ErrTooLarge
+	}
+	if ok, _ := cq.bound.acquire(cost, cq.bound.limit); !ok {
+		return ErrFull
+	}
+	return cq.enqueueAcquired(elements, nil, cost, time.Time{})
+}
+
+// EnqueueWait adds the input elements to a bounded queue, waiting until they all fit.
+// If ctx is done first it writes none of them and returns the context's error. A batch that
+// can never fit isn't written either, and ErrTooLarge is returned right away.
+// If the queue is closed, before or while waiting, it returns ErrClosed.
+// On an unbounded queue it is the same as Enqueue.
+func (cq *Cirque[T]) EnqueueWait(ctx context.Context, elements ...T) error {
+	return cq.enqueueWait(ctx, elements, nil, time.Time{})
+}
+
+// Enqueue elements that expire at expires, unless it's zero, waiting for room on a bounded queue.
+// Unless seqs is nil, it is filled with their sequence numbers.
+func (cq *Cirque[T]) enqueueWait(ctx context.Context, elements []T, seqs []uint64, expires time.Time) error {
+	if cq.bound.limit == 0 {
+		_, err := cq.enqueue(elements, seqs, expires)
+		return err
+	}
+
+	if cq.isClosed() {
+		return ErrClosed
+	}
+	cost := cq.bound.costOf(elements)
+	if cost > cq.bound.ceiling() {
+		return ErrTooLarge
+	}
+
+	// Writers that wait long enough may go past the limit, if bursts are allowed.
+	limit := cq.bound.limit
+	var burst <-chan struct{}
+	if cq.bound.burstAfter > 0 {
+		var timer clock.Timer
+		burst, timer = clock.After(cq.clock, cq.bound.burstAfter)
+		defer timer.Stop()
+	}
+
+	for {
+		ok, freed := cq.bound.acquireSpinning(cost, limit)
+		if ok {
+			break
+		}
+
+		log.Debugf("Waiting for room for %d items.", len(elements))
+		reach(Wait)
+		select {
+		case <-freed:
+		case <-burst:
+			log.Debugf("Letting %d items past the limit after waiting for %v.", len(elements), cq.bound.burstAfter)
+			limit, burst = cq.bound.ceiling(), nil
+		case <-cq.done:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return cq.enqueueAcquired(elements, seqs, cost, expires)
+}
+
+// Enqueue elements whose cost was already taken out of the limit, giving back that of rejected ones.
+func (cq *Cirque[T]) enqueueAcquired(elements []T, seqs []uint64, cost int64, expires time.Time) error {
+	admitted, err := cq.enqueue(elements, seqs, expires)
+	if len(admitted) < len(elements) {
+		cq.bound.release(cost - cq.bound.costOf(admitted))
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTryEnqueueAllOrNothing(t *testing.T) {
+	cq := New(2, WithBound[int](3))
+
+	if err := cq.TryEnqueue(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := cq.TryEnqueue(3, 4); !errors.Is(err, ErrFull) {
+		t.Fatal("Expected a batch that doesn't fit to be refused.")
+	}
+	if err := cq.TryEnqueue(1, 2, 3, 4); !errors.Is(err, ErrTooLarge) {
+		t.Fatal("Expected a batch larger than the bound to be refused.")
+	}
+	if err := cq.TryEnqueue(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{1, 2, 3}) {
+		t.Fatalf("Expected no partial batches, got %v.", items)
+	}
+}
+
+func TestEnqueueWait(t *testing.T) {
+	cq := New(4, WithBound[int](2))
+	cq.Enqueue(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cq.EnqueueWait(ctx, 2, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected the writer to wait for room.")
+	}
+	if cq.Len() != 1 {
+		t.Fatal("Expected nothing of the batch to be written.")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cq.Dequeue(1)
+	}()
+	if err := cq.EnqueueWait(context.Background(), 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{2, 3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestByteBudget(t *testing.T) {
+	cq := New(4, WithByteBudget(10, func(s string) int64 { return int64(len(s)) }))
+
+	if err := cq.TryEnqueue("aaaa", "bbbb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cq.TryEnqueue("ccc"); !errors.Is(err, ErrFull) {
+		t.Fatal("Expected the byte budget to be enforced.")
+	}
+	if err := cq.TryEnqueue("cc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cq.TryEnqueue("elevenbytes"); !errors.Is(err, ErrTooLarge) {
+		t.Fatal("Expected an item larger than the budget to be refused.")
+	}
+
+	// Reading frees the bytes of the items read.
+	cq.Dequeue(1)
+	if err := cq.TryEnqueue("ccc"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWeightLimit(t *testing.T) {
+	type job struct{ units int64 }
+	cq := New(4, WithWeightLimit(10, func(j job) int64 { return j.units }))
+
+	if err := cq.TryEnqueue(job{3}, job{5}); err != nil {
+		t.Fatal(err)
+	}
+	if used, limit := cq.Weight(); used != 8 || limit != 10 {
+		t.Fatalf("Unexpected weight %d of %d.", used, limit)
+	}
+
+	// A blocked writer proceeds once enough weight is read.
+	done := make(chan error)
+	go func() { done <- cq.EnqueueWait(context.Background(), job{4}) }()
+	cq.Dequeue(1)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if used, _ := cq.Weight(); used != 9 {
+		t.Fatalf("Unexpected weight %d.", used)
+	}
+}
+
+func TestSpinWait(t *testing.T) {
+	cq := New(4, WithBound[int](1), WithSpinWait[int](100, 100))
+	cq.Enqueue(1)
+
+	done := make(chan error)
+	go func() {
+		done <- cq.EnqueueWait(context.Background(), 2)
+	}()
+	cq.Dequeue(1)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cq.EnqueueWait(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected the writer to park after spinning.")
+	}
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{2}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestBurst(t *testing.T) {
+	cq := New(4, WithBound[int](2), WithBurst[int](10*time.Millisecond, 3))
+	cq.Enqueue(1, 2)
+
+	// A writer that waits long enough goes past the limit, up to the burst limit.
+	if err := cq.EnqueueWait(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	if used, limit := cq.Weight(); used != 3 || limit != 2 {
+		t.Fatalf("Expected a weight of 3 over a limit of 2, got %d and %d.", used, limit)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := cq.EnqueueWait(ctx, 4); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected the writer to wait at the burst limit.")
+	}
+	if err := cq.TryEnqueue(4); !errors.Is(err, ErrFull) {
+		t.Fatal("Expected writers that don't wait to stay within the limit.")
+	}
+	if err := cq.EnqueueWait(context.Background(), 4, 5, 6, 7); !errors.Is(err, ErrTooLarge) {
+		t.Fatal("Expected a batch larger than the burst limit to be refused.")
+	}
+
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{1, 2, 3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
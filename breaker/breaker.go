@@ -0,0 +1,139 @@
+// Package breaker provides a rolling window of call outcomes to back circuit breakers.
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// Window keeps the outcomes of the last n calls, or of the calls of the last d duration,
+// in a ring, along with running counts of successes and failures, so every operation is
+// O(1) amortized.
+type Window struct {
+	mu       sync.Mutex
+	d        time.Duration
+	outcomes []outcome
+	start    int // Position of the oldest outcome
+	len      int
+	failures int // Number of failures among the outcomes in the window
+	clock    clock.Clock
+}
+
+type outcome struct {
+	failed bool
+	at     time.Time
+}
+
+// Option configures a Window.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the window tell the current time with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// New creates a Window over the last n calls, dropping calls older than d as well unless d is zero.
+func New(n int, d time.Duration, opts ...Option) *Window {
+	if n <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Window{d: d, outcomes: make([]outcome, n), clock: cfg.clock}
+}
+
+// Success records a successful call.
+func (w *Window) Success() {
+	w.RecordAt(true, w.clock.Now())
+}
+
+// Failure records a failed call.
+func (w *Window) Failure() {
+	w.RecordAt(false, w.clock.Now())
+}
+
+// RecordAt records the outcome of a call made at t. Calls are expected in time order.
+func (w *Window) RecordAt(ok bool, t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.expire(t)
+	if w.len == len(w.outcomes) {
+		w.drop()
+	}
+	w.outcomes[(w.start+w.len)%len(w.outcomes)] = outcome{failed: !ok, at: t}
+	w.len++
+	if !ok {
+		w.failures++
+	}
+}
+
+// Must be called with mu held.
+func (w *Window) expire(now time.Time) {
+	if w.d <= 0 {
+		return
+	}
+	cutoff := now.Add(-w.d)
+	for w.len > 0 && w.outcomes[w.start].at.Before(cutoff) {
+		w.drop()
+	}
+}
+
+// Remove the oldest outcome. Must be called with mu held.
+func (w *Window) drop() {
+	if w.outcomes[w.start].failed {
+		w.failures--
+	}
+	w.start = (w.start + 1) % len(w.outcomes)
+	w.len--
+}
+
+// Counts returns the number of successful and failed calls in the window ending now.
+func (w *Window) Counts() (successes, failures int) {
+	return w.CountsAt(w.clock.Now())
+}
+
+// CountsAt returns the number of successful and failed calls in the window ending at now.
+func (w *Window) CountsAt(now time.Time) (successes, failures int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.expire(now)
+	return w.len - w.failures, w.failures
+}
+
+// FailureRatio returns the fraction of failed calls in the window ending now, or 0 if there were none.
+func (w *Window) FailureRatio() float64 {
+	successes, failures := w.Counts()
+	if successes+failures == 0 {
+		return 0
+	}
+	return float64(failures) / float64(successes+failures)
+}
+
+// Exceeds reports whether more than ratio of the calls in the window ending now failed,
+// considering only windows with at least minCalls calls, so that a single early failure
+// doesn't trip a breaker.
+func (w *Window) Exceeds(ratio float64, minCalls int) bool {
+	successes, failures := w.Counts()
+	total := successes + failures
+	return total > 0 && total >= minCalls && float64(failures) > ratio*float64(total)
+}
+
+// Reset forgets all recorded outcomes, like when a breaker closes again.
+func (w *Window) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.start, w.len, w.failures = 0, 0, 0
+}
@@ -0,0 +1,71 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestCountWindow(t *testing.T) {
+	w := New(4, 0)
+
+	w.Failure()
+	if w.Exceeds(0.5, 3) {
+		t.Fatal("Expected too few calls to trip the breaker.")
+	}
+
+	w.Success()
+	w.Failure()
+	w.Failure()
+	if !w.Exceeds(0.5, 3) || w.FailureRatio() != 0.75 {
+		t.Fatalf("Expected 75%% failures, got %v.", w.FailureRatio())
+	}
+
+	// Successes push the old failures out of the window.
+	w.Success()
+	w.Success()
+	w.Success()
+	if s, f := w.Counts(); s != 3 || f != 1 {
+		t.Fatalf("Expected 3 successes and 1 failure, got %d and %d.", s, f)
+	}
+	if w.Exceeds(0.5, 3) {
+		t.Fatal("Expected the breaker not to trip.")
+	}
+
+	w.Reset()
+	if s, f := w.Counts(); s != 0 || f != 0 {
+		t.Fatal("Expected Reset to forget everything.")
+	}
+}
+
+func TestDurationWindow(t *testing.T) {
+	w := New(100, 10*time.Second)
+	base := time.Unix(1000, 0)
+
+	w.RecordAt(false, base)
+	w.RecordAt(false, base.Add(time.Second))
+	w.RecordAt(true, base.Add(5*time.Second))
+
+	if _, f := w.CountsAt(base.Add(10500 * time.Millisecond)); f != 1 {
+		t.Fatalf("Expected the first failure to expire, got %d failures.", f)
+	}
+}
+
+func TestCoolDown(t *testing.T) {
+	c := clock.NewFake(time.Unix(1000, 0))
+	w := New(100, 10*time.Second, WithClock(c))
+
+	w.Failure()
+	w.Failure()
+	if !w.Exceeds(0.5, 2) {
+		t.Fatal("Expected the breaker to trip.")
+	}
+
+	// Once the failures age out of the window, the breaker cools down.
+	c.Advance(11 * time.Second)
+	w.Success()
+	if w.Exceeds(0.5, 1) {
+		t.Fatalf("Expected the failures to expire, got a failure ratio of %v.", w.FailureRatio())
+	}
+}
@@ -0,0 +1,379 @@
+// Package broadcast provides a bounded ring buffer that fans out every item to
+// multiple independent readers.
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrClosed is returned by writes to a closed Buffer and by reads once a closed
+// Buffer has no more items for the reader.
+var ErrClosed = errors.New("broadcast: buffer closed")
+
+// Option configures a Buffer.
+type Option func(*config)
+
+type config struct {
+	overwrite   bool
+	retainCount int
+	retainAge   time.Duration
+	clock       clock.Clock
+}
+
+// WithOverwrite makes the writer overwrite the oldest items instead of waiting for
+// the slowest reader. Readers that get lapped skip forward to the oldest retained item.
+func WithOverwrite() Option {
+	return func(c *config) {
+		c.overwrite = true
+	}
+}
+
+// WithClock makes the buffer timestamp items with c instead of the real clock, which is what
+// retention by age and seeking by time go by.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// Buffer is a fixed-capacity ring buffer where every reader has its own cursor and
+// sees every item written after it joined.
+//
+// Items are addressed by a monotonically increasing sequence number. The buffer
+// retains the last n written items, so slot of item s is always s % n.
+type Buffer[T any] struct {
+	mu      sync.Mutex
+	slots   []T
+	times   []time.Time // Write time of the item in the same slot
+	tail    uint64      // Sequence number of the next item to be written
+	readers map[*Reader[T]]struct{}
+	cursors map[string]*Reader[T] // Named readers, also present in readers
+	groups  map[string]*Group[T]
+	closed  bool
+	changed chan struct{} // Closed and replaced whenever readers or writers make progress
+	stats   Stats
+	config
+}
+
+// Stats holds cumulative counters of a Buffer since its creation.
+type Stats struct {
+	Written     uint64        // Number of items written
+	Read        uint64        // Number of items read, summed over all readers
+	Skipped     uint64        // Number of items readers lost to being lapped or to retention, summed over all readers
+	Blocks      uint64        // Number of times the writer had to wait for the slowest reader
+	BlockedTime time.Duration // Total time the writer spent waiting for the slowest reader
+}
+
+// Stats returns the counters of the buffer.
+func (b *Buffer[T]) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Lag returns the number of retained items that the slowest reader or group still has to read,
+// which is how far consumers are behind the writer.
+func (b *Buffer[T]) Lag() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.tail - max(b.slowest(), b.head()))
+}
+
+// Occupancy returns the lag as a fraction of the capacity of the buffer. Without WithOverwrite
+// or retention, the writer blocks once it reaches 1, so consumers that persistently stay close
+// to it hold back the writer.
+func (b *Buffer[T]) Occupancy() float64 {
+	return float64(b.Lag()) / float64(len(b.slots))
+}
+
+// New creates a Buffer of capacity n with items of type T.
+func New[T any](n int, opts ...Option) *Buffer[T] {
+	if n <= 0 {
+		return nil
+	}
+	b := &Buffer[T]{
+		slots:   make([]T, n),
+		times:   make([]time.Time, n),
+		readers: make(map[*Reader[T]]struct{}),
+		cursors: make(map[string]*Reader[T]),
+		groups:  make(map[string]*Group[T]),
+		changed: make(chan struct{}),
+		config:  config{clock: clock.Real},
+	}
+	for _, opt := range opts {
+		opt(&b.config)
+	}
+	return b
+}
+
+// Cap returns the capacity of the buffer.
+func (b *Buffer[T]) Cap() int {
+	return len(b.slots)
+}
+
+// Must be called with mu held.
+func (b *Buffer[T]) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Sequence number of the oldest item still retained in the buffer.
+// Must be called with mu held.
+func (b *Buffer[T]) head() uint64 {
+	var head uint64
+	if n := uint64(len(b.slots)); b.tail > n {
+		head = b.tail - n
+	}
+	if n := uint64(b.retainCount); n > 0 && b.tail > n {
+		head = max(head, b.tail-n)
+	}
+	if b.retainAge > 0 {
+		head = b.since(head, b.clock.Now().Add(-b.retainAge))
+	}
+	return head
+}
+
+// Sequence number of the slowest attached reader or committed group offset, or tail if there are none.
+// Must be called with mu held.
+func (b *Buffer[T]) slowest() uint64 {
+	min := b.tail
+	for r := range b.readers {
+		if r.next < min {
+			min = r.next
+		}
+	}
+	for _, g := range b.groups {
+		if g.committed < min {
+			min = g.committed
+		}
+	}
+	return min
+}
+
+// Must be called with mu held.
+func (b *Buffer[T]) block(start time.Time) {
+	b.stats.Blocks++
+	b.stats.BlockedTime += time.Since(start)
+}
+
+// Write appends items to the buffer. Unless the buffer was created with WithOverwrite,
+// Write blocks while the slowest reader still has to read the slot about to be reused.
+// It returns early with the context's error if ctx is done, in which case only some
+// of the items may have been written.
+func (b *Buffer[T]) Write(ctx context.Context, items ...T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, item := range items {
+		for {
+			if b.closed {
+				return ErrClosed
+			}
+			if b.overwrite || b.retaining() || b.tail-b.slowest() < uint64(len(b.slots)) {
+				break
+			}
+
+			// The slowest reader hasn't read the oldest slot yet, so let readers know
+			// about the items written so far and wait for them.
+			b.notify()
+			changed := b.changed
+			b.mu.Unlock()
+			start := time.Now()
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				b.mu.Lock()
+				b.block(start)
+				return ctx.Err()
+			}
+			b.mu.Lock()
+			b.block(start)
+		}
+
+		b.slots[b.tail%uint64(len(b.slots))] = item
+		b.times[b.tail%uint64(len(b.slots))] = b.clock.Now()
+		b.tail++
+		b.stats.Written++
+	}
+
+	b.notify()
+	return nil
+}
+
+// Close closes the buffer for writing. Readers can still read the items they haven't
+// seen yet, after which they get ErrClosed. Close is idempotent.
+func (b *Buffer[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	b.notify()
+}
+
+// NewReader attaches a new reader to the buffer. The reader starts at the end of the
+// buffer, so it sees only items written after this call.
+func (b *Buffer[T]) NewReader() *Reader[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := &Reader[T]{b: b, next: b.tail}
+	b.readers[r] = struct{}{}
+	log.Debugf("Attached reader, %d readers total.", len(b.readers))
+	return r
+}
+
+// Cursor returns the reader with the given name, attaching a new one if it doesn't exist yet.
+// This lets independent consumers, like an exporter and a debugger, share one buffer without
+// passing readers around. Items are only reclaimed once every cursor has read them.
+func (b *Buffer[T]) Cursor(name string) *Reader[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if r, ok := b.cursors[name]; ok {
+		return r
+	}
+
+	r := &Reader[T]{b: b, name: name, next: b.tail}
+	b.readers[r] = struct{}{}
+	b.cursors[name] = r
+	log.Debugf("Attached cursor %q, %d readers total.", name, len(b.readers))
+	return r
+}
+
+// Reader is a cursor over a Buffer. A Reader must not be used from multiple goroutines
+// concurrently.
+type Reader[T any] struct {
+	b       *Buffer[T]
+	name    string // Empty unless attached with Cursor
+	next    uint64 // Sequence number of the next item to read
+	skipped uint64 // Items lost right before those returned by the last Read
+}
+
+// Name returns the name the reader was attached with, or an empty string for readers
+// attached with NewReader.
+func (r *Reader[T]) Name() string {
+	return r.name
+}
+
+// Read returns up to max items, blocking until at least one is available.
+// Once the buffer is closed and the reader has seen every item, Read returns ErrClosed.
+// If retention reclaimed items the reader hasn't read, Read returns ErrFellBehind and moves
+// the reader to the oldest retained item. Skipped tells how many items were lost either way.
+func (r *Reader[T]) Read(ctx context.Context, max int) ([]T, error) {
+	if max <= 0 {
+		return nil, nil
+	}
+
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r.skipped = 0
+	attached := func() bool {
+		_, ok := b.readers[r]
+		return ok
+	}
+	if err := b.await(ctx, &r.next, attached); err != nil {
+		return nil, err
+	}
+	items, skipped, err := b.take(&r.next, max)
+	r.skipped = skipped
+	return items, err
+}
+
+// Lag returns the number of retained items the reader still has to read.
+func (r *Reader[T]) Lag() int {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.tail - max(r.next, b.head()))
+}
+
+// Skipped returns the number of items the reader lost since the previous Read because the writer
+// lapped it in overwrite mode, or retention reclaimed them, which were due right before the items
+// returned by the last Read.
+func (r *Reader[T]) Skipped() uint64 {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return r.skipped
+}
+
+// await waits until there are items past next, returning ErrClosed if the buffer gets closed
+// or the cursor detached before that.
+// Must be called with mu held.
+func (b *Buffer[T]) await(ctx context.Context, next *uint64, attached func() bool) error {
+	for *next == b.tail {
+		if b.closed || !attached() {
+			return ErrClosed
+		}
+
+		changed := b.changed
+		b.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			b.mu.Lock()
+			return ctx.Err()
+		}
+		b.mu.Lock()
+	}
+	return nil
+}
+
+// take returns up to max items starting from next, and moves next past them,
+// along with the number of items skipped because they were no longer retained.
+// Must be called with mu held.
+func (b *Buffer[T]) take(next *uint64, max int) ([]T, uint64, error) {
+	var skipped uint64
+	if head := b.head(); *next < head {
+		// The writer lapped the reader, or retention reclaimed items it hasn't read.
+		skipped = head - *next
+		log.Debugf("Reader fell behind, skipping %d items.", skipped)
+		*next = head
+		b.stats.Skipped += skipped
+		if b.retaining() {
+			return nil, skipped, ErrFellBehind
+		}
+	}
+
+	n := b.tail - *next
+	if n > uint64(max) {
+		n = uint64(max)
+	}
+	result := make([]T, 0, n)
+	for i := uint64(0); i < n; i++ {
+		result = append(result, b.slots[(*next+i)%uint64(len(b.slots))])
+	}
+	*next += n
+	b.stats.Read += n
+
+	// Wake up a writer that may be waiting on this reader.
+	b.notify()
+	return result, skipped, nil
+}
+
+// Close detaches the reader from the buffer, so that the writer no longer waits for it.
+func (r *Reader[T]) Close() {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.readers[r]; !ok {
+		return
+	}
+	delete(b.readers, r)
+	if r.name != "" {
+		delete(b.cursors, r.name)
+	}
+	b.notify()
+}
@@ -0,0 +1,211 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestFanOut(t *testing.T) {
+	b := New[int](4)
+	n := 100
+
+	var readers []*Reader[int]
+	for i := 0; i < 3; i++ {
+		readers = append(readers, b.NewReader())
+	}
+
+	var wg sync.WaitGroup
+	for _, r := range readers {
+		wg.Add(1)
+		go func(r *Reader[int]) {
+			defer wg.Done()
+			expected := 0
+			for {
+				items, err := r.Read(context.Background(), 3)
+				if errors.Is(err, ErrClosed) {
+					break
+				}
+				for _, item := range items {
+					if item != expected {
+						t.Error("Items missing or reordered.")
+						return
+					}
+					expected++
+				}
+			}
+			if expected != n {
+				t.Errorf("Expected %d items, got %d.", n, expected)
+			}
+		}(r)
+	}
+
+	for i := 0; i < n; i++ {
+		if err := b.Write(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Close()
+	wg.Wait()
+
+	if err := b.Write(context.Background(), 0); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected write after close to fail.")
+	}
+}
+
+func TestOverwrite(t *testing.T) {
+	b := New[int](4, WithOverwrite())
+	r := b.NewReader()
+
+	// The writer must not block on the reader.
+	for i := 0; i < 10; i++ {
+		if err := b.Write(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := r.Read(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || items[0] != 6 || items[3] != 9 {
+		t.Fatalf("Expected only the last 4 items, got %v.", items)
+	}
+	if r.Skipped() != 6 || b.Stats().Skipped != 6 {
+		t.Fatalf("Expected 6 skipped items, got %d.", r.Skipped())
+	}
+
+	// Reads that keep up don't skip anything.
+	b.Write(context.Background(), 10)
+	if _, err := r.Read(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+	if r.Skipped() != 0 {
+		t.Fatalf("Expected no skipped items, got %d.", r.Skipped())
+	}
+}
+
+func TestWriteCancelled(t *testing.T) {
+	b := New[int](1)
+	b.NewReader()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := b.Write(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Write(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected blocked write to be cancelled.")
+	}
+}
+
+func TestWriteBatchLargerThanCapacity(t *testing.T) {
+	b := New[int](2)
+	r := b.NewReader()
+
+	done := make(chan error)
+	go func() {
+		done <- b.Write(context.Background(), 0, 1, 2, 3, 4)
+	}()
+
+	var got []int
+	for len(got) < 5 {
+		items, err := r.Read(context.Background(), 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, items...)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for i, item := range got {
+		if item != i {
+			t.Fatal("Items missing or reordered.")
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	b := New[int](1)
+	r := b.NewReader()
+
+	done := make(chan error)
+	go func() {
+		done <- b.Write(context.Background(), 0, 1)
+	}()
+	for i := 0; i < 2; {
+		items, err := r.Read(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i += len(items)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	s := b.Stats()
+	if s.Written != 2 || s.Read != 2 || s.Blocks == 0 {
+		t.Fatalf("Unexpected counters: %+v.", s)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	b := New[int](2)
+	exporter := b.Cursor("exporter")
+	debugger := b.Cursor("debugger")
+	if b.Cursor("exporter") != exporter || exporter.Name() != "exporter" {
+		t.Fatal("Expected the same cursor for the same name.")
+	}
+
+	if err := b.Write(context.Background(), 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exporter.Read(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// The debugger hasn't read the items yet, so they can't be reclaimed.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Write(ctx, 2); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected the writer to wait for the slowest cursor.")
+	}
+
+	items, err := debugger.Read(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0] != 0 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if err := b.Write(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// A closed cursor's name can be reused.
+	debugger.Close()
+	if b.Cursor("debugger") == debugger {
+		t.Fatal("Expected a new cursor after closing.")
+	}
+}
+
+func TestLag(t *testing.T) {
+	b := New[int](4, WithOverwrite())
+	fast, slow := b.NewReader(), b.NewReader()
+
+	b.Write(context.Background(), 1, 2, 3)
+	fast.Read(context.Background(), 2)
+	if fast.Lag() != 1 || slow.Lag() != 3 || b.Lag() != 3 || b.Occupancy() != 0.75 {
+		t.Fatalf("Unexpected lag %d, %d and %d.", fast.Lag(), slow.Lag(), b.Lag())
+	}
+
+	// Items lost to overwriting don't count.
+	b.Write(context.Background(), 4, 5, 6)
+	if slow.Lag() != 4 || b.Occupancy() != 1 {
+		t.Fatalf("Expected the lag to stop at the capacity, got %d.", slow.Lag())
+	}
+}
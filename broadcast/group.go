@@ -0,0 +1,130 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrInvalidOffset is returned when committing an offset that hasn't been read yet.
+var ErrInvalidOffset = errors.New("broadcast: offset not read yet")
+
+// Group is a named consumer of a Buffer that tracks a committed offset.
+//
+// Unlike a Reader, the writer waits for the committed offset rather than for what has been read,
+// so items that were read but not committed are still retained and can be read again after
+// the group is reattached with Buffer.Group.
+//
+// Offsets are item sequence numbers, so the offset to commit after processing a batch is the
+// one returned along with it by Read.
+type Group[T any] struct {
+	b         *Buffer[T]
+	name      string
+	next      uint64 // Sequence number of the next item to read
+	committed uint64 // Sequence number of the first item not yet committed
+}
+
+// Group returns the consumer group with the given name, creating it if it doesn't exist yet.
+// A new group starts at the end of the buffer, so it sees only items written after this call.
+// An existing group is rewound to its committed offset, so that a consumer that restarts
+// resumes from the first item it hasn't committed.
+func (b *Buffer[T]) Group(name string) *Group[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if g, ok := b.groups[name]; ok {
+		log.Debugf("Rewinding group %q by %d items.", name, g.next-g.committed)
+		g.next = g.committed
+		return g
+	}
+
+	g := &Group[T]{b: b, name: name, next: b.tail, committed: b.tail}
+	b.groups[name] = g
+	log.Debugf("Created group %q, %d groups total.", name, len(b.groups))
+	return g
+}
+
+// RemoveGroup deletes the consumer group with the given name, so that the writer no longer
+// waits for it.
+func (b *Buffer[T]) RemoveGroup(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.groups[name]; !ok {
+		return
+	}
+	delete(b.groups, name)
+	b.notify()
+}
+
+// Offsets returns the committed offset of every group.
+func (b *Buffer[T]) Offsets() map[string]uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	offsets := make(map[string]uint64, len(b.groups))
+	for name, g := range b.groups {
+		offsets[name] = g.committed
+	}
+	return offsets
+}
+
+// Name returns the name of the group.
+func (g *Group[T]) Name() string {
+	return g.name
+}
+
+// Read returns up to max items, blocking until at least one is available, along with the
+// offset to commit once they have been processed.
+// Once the buffer is closed and the group has seen every item, Read returns ErrClosed.
+// If retention reclaimed items the group hasn't read, Read returns ErrFellBehind and moves
+// the group to the oldest retained item.
+// Concurrent calls hand out disjoint batches of items.
+func (g *Group[T]) Read(ctx context.Context, max int) ([]T, uint64, error) {
+	b := g.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if max <= 0 {
+		return nil, g.next, nil
+	}
+
+	attached := func() bool {
+		return b.groups[g.name] == g
+	}
+	if err := b.await(ctx, &g.next, attached); err != nil {
+		return nil, g.next, err
+	}
+	items, _, err := b.take(&g.next, max)
+	if head := b.head(); g.committed < head {
+		// Reclaimed items can't be read again.
+		g.committed = head
+	}
+	return items, g.next, err
+}
+
+// Commit marks every item before offset as processed, allowing the writer to reuse their slots.
+// Committing an offset older than the current one has no effect.
+func (g *Group[T]) Commit(offset uint64) error {
+	b := g.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if offset > g.next {
+		return ErrInvalidOffset
+	}
+	if offset > g.committed {
+		g.committed = offset
+		b.notify()
+	}
+	return nil
+}
+
+// Committed returns the committed offset of the group.
+func (g *Group[T]) Committed() uint64 {
+	b := g.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return g.committed
+}
@@ -0,0 +1,64 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGroupCommit(t *testing.T) {
+	b := New[int](4)
+	g := b.Group("exporter")
+
+	if err := b.Write(context.Background(), 0, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	items, offset, err := g.Read(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0] != 0 || offset != 2 {
+		t.Fatalf("Unexpected read %v at offset %d.", items, offset)
+	}
+	if err := g.Commit(offset + 1); !errors.Is(err, ErrInvalidOffset) {
+		t.Fatal("Expected commit past the read items to fail.")
+	}
+	if err := g.Commit(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted consumer resumes from the first uncommitted item.
+	g = b.Group("exporter")
+	items, offset, err = g.Read(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0] != 1 || offset != 3 {
+		t.Fatalf("Unexpected read %v at offset %d.", items, offset)
+	}
+
+	// The writer waits for the committed offset, not the read one.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Write(ctx, 3, 4, 5); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected the writer to wait for the uncommitted items.")
+	}
+	if err := g.Commit(offset); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Write(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if b.Offsets()["exporter"] != 3 {
+		t.Fatal("Unexpected committed offset.")
+	}
+
+	b.RemoveGroup("exporter")
+	if _, _, err := g.Read(context.Background(), 10); err != nil {
+		t.Fatal("Expected buffered items to still be readable.")
+	}
+	if _, _, err := g.Read(context.Background(), 10); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected a removed group to be closed.")
+	}
+}
@@ -0,0 +1,25 @@
+package broadcast
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFellBehind is returned by Read when retention reclaimed items the reader hadn't read yet.
+// The next Read continues from the oldest retained item.
+var ErrFellBehind = errors.New("broadcast: reader fell behind the retained items")
+
+// WithRetention makes the buffer keep at most count items and only items written within
+// the last age, whichever is fewer. Zero means no limit. Like with WithOverwrite, the writer
+// never waits for readers, but readers that miss reclaimed items are told with ErrFellBehind.
+func WithRetention(count int, age time.Duration) Option {
+	return func(c *config) {
+		c.retainCount = count
+		c.retainAge = age
+	}
+}
+
+// Must be called with mu held.
+func (b *Buffer[T]) retaining() bool {
+	return b.retainCount > 0 || b.retainAge > 0
+}
@@ -0,0 +1,59 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestRetentionCount(t *testing.T) {
+	b := New[int](8, WithRetention(3, 0))
+	r := b.NewReader()
+
+	// The writer doesn't wait for the reader.
+	if err := b.Write(context.Background(), 0, 1, 2, 3, 4, 5, 6, 7, 8, 9); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Read(context.Background(), 10); !errors.Is(err, ErrFellBehind) {
+		t.Fatal("Expected the reader to fall behind.")
+	}
+	items, err := r.Read(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0] != 7 {
+		t.Fatalf("Expected only the last 3 items, got %v.", items)
+	}
+}
+
+func TestRetentionAge(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	b := New[int](8, WithRetention(0, time.Minute), WithClock(c))
+	g := b.Group("exporter")
+
+	if err := b.Write(context.Background(), 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(time.Minute + time.Second)
+	if err := b.Write(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := g.Read(context.Background(), 10); !errors.Is(err, ErrFellBehind) {
+		t.Fatal("Expected the group to fall behind.")
+	}
+	if g.Committed() != 2 {
+		t.Fatal("Expected the committed offset to move past the reclaimed items.")
+	}
+	items, _, err := g.Read(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != 2 {
+		t.Fatalf("Expected only the recent item, got %v.", items)
+	}
+}
@@ -0,0 +1,115 @@
+package broadcast
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrOutOfRange is returned when seeking to an offset outside the retained items.
+var ErrOutOfRange = errors.New("broadcast: offset outside the retained items")
+
+// Retained returns the offsets of the oldest retained item and of the next item to be written.
+func (b *Buffer[T]) Retained() (head, tail uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head(), b.tail
+}
+
+// Must be called with mu held.
+func (b *Buffer[T]) checkOffset(offset uint64) error {
+	if offset < b.head() || offset > b.tail {
+		return ErrOutOfRange
+	}
+	return nil
+}
+
+// Offset of the oldest retained item written at or after t, or tail if there is none.
+// Must be called with mu held.
+func (b *Buffer[T]) search(t time.Time) uint64 {
+	return b.since(b.head(), t)
+}
+
+// Offset of the first item from head onwards written at or after t, or tail if there is none.
+// Must be called with mu held.
+func (b *Buffer[T]) since(head uint64, t time.Time) uint64 {
+	i := sort.Search(int(b.tail-head), func(i int) bool {
+		return !b.times[(head+uint64(i))%uint64(len(b.slots))].Before(t)
+	})
+	return head + uint64(i)
+}
+
+// Offset returns the offset of the next item the reader will read.
+func (r *Reader[T]) Offset() uint64 {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return r.next
+}
+
+// Seek moves the reader to the given offset, which must be within the retained items.
+// Seeking back makes the writer wait for the reader to read the items again.
+func (r *Reader[T]) Seek(offset uint64) error {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkOffset(offset); err != nil {
+		return err
+	}
+	r.seek(offset)
+	return nil
+}
+
+// SeekTime moves the reader to the oldest retained item written at or after t,
+// or to the end of the buffer if there is none.
+func (r *Reader[T]) SeekTime(t time.Time) {
+	b := r.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r.seek(b.search(t))
+}
+
+// Must be called with mu held.
+func (r *Reader[T]) seek(offset uint64) {
+	log.Debugf("Reader seeking from %d to %d.", r.next, offset)
+	r.next = offset
+	r.b.notify()
+}
+
+// Seek moves the group to the given offset, which must be within the retained items.
+// Seeking back before the committed offset also moves the committed offset back.
+func (g *Group[T]) Seek(offset uint64) error {
+	b := g.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkOffset(offset); err != nil {
+		return err
+	}
+	g.seek(offset)
+	return nil
+}
+
+// SeekTime moves the group to the oldest retained item written at or after t,
+// or to the end of the buffer if there is none.
+func (g *Group[T]) SeekTime(t time.Time) {
+	b := g.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g.seek(b.search(t))
+}
+
+// Must be called with mu held.
+func (g *Group[T]) seek(offset uint64) {
+	log.Debugf("Group %q seeking from %d to %d.", g.name, g.next, offset)
+	g.next = offset
+	if offset < g.committed {
+		g.committed = offset
+	}
+	g.b.notify()
+}
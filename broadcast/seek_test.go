@@ -0,0 +1,72 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestSeek(t *testing.T) {
+	b := New[int](4, WithOverwrite())
+	r := b.NewReader()
+
+	if err := b.Write(context.Background(), 0, 1, 2, 3, 4, 5); err != nil {
+		t.Fatal(err)
+	}
+	if head, tail := b.Retained(); head != 2 || tail != 6 {
+		t.Fatalf("Unexpected retained offsets %d to %d.", head, tail)
+	}
+
+	if err := r.Seek(1); !errors.Is(err, ErrOutOfRange) {
+		t.Fatal("Expected seeking past the retained items to fail.")
+	}
+	if err := r.Seek(4); err != nil {
+		t.Fatal(err)
+	}
+	items, err := r.Read(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0] != 4 || r.Offset() != 6 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestSeekTime(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	b := New[int](8, WithClock(c))
+	g := b.Group("debugger")
+
+	if err := b.Write(context.Background(), 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	c.Advance(time.Second)
+	since := c.Now()
+	if err := b.Write(context.Background(), 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := g.Read(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Commit(4); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replaying the items since a point in time also rewinds the committed offset.
+	g.SeekTime(since)
+	if g.Committed() != 2 {
+		t.Fatalf("Expected committed offset 2, got %d.", g.Committed())
+	}
+	items, _, err := g.Read(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0] != 2 || items[1] != 3 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+
+}
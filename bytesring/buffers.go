@@ -0,0 +1,45 @@
+package bytesring
+
+// WriteBuffers writes the contents of bufs into the ring one after the other, as a single write.
+// It accepts net.Buffers, so scatter/gather producers don't have to join a header and
+// payload first.
+//
+// If the total fits into the ring, WriteBuffers waits for enough free space and makes
+// all of it visible to readers at once. Otherwise it writes as space frees up, like Write.
+// Either way no other write gets in between.
+func (r *Ring) WriteBuffers(bufs [][]byte) (int64, error) {
+	total := 0
+	for _, b := range bufs {
+		total += len(b)
+	}
+
+	r.wmu.Lock()
+	defer r.wmu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if total <= len(r.buf) {
+		for !r.closed && r.free() < total {
+			r.cond.Wait()
+		}
+		if r.closed {
+			return 0, ErrClosed
+		}
+		for _, b := range bufs {
+			r.put(b)
+		}
+		r.cond.Broadcast()
+		return int64(total), nil
+	}
+
+	var written int64
+	for _, b := range bufs {
+		n, err := r.write(b)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,30 @@
+package bytesring
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+func TestWriteBuffers(t *testing.T) {
+	r := New(8)
+
+	n, err := r.WriteBuffers(net.Buffers{[]byte("head"), []byte("body")})
+	if err != nil || n != 8 {
+		t.Fatalf("Unexpected write %d, %v.", n, err)
+	}
+	if a, _ := r.ReadSlices(8); string(a) != "headbody" {
+		t.Fatalf("Unexpected contents %q.", a)
+	}
+	r.Commit(8)
+
+	// Larger than the ring, so it has to be streamed.
+	go func() {
+		r.WriteBuffers(net.Buffers{[]byte("0123456789"), []byte("abcdef")})
+		r.Close()
+	}()
+	b, err := io.ReadAll(r)
+	if err != nil || string(b) != "0123456789abcdef" {
+		t.Fatalf("Unexpected read %q, %v.", b, err)
+	}
+}
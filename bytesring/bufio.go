@@ -0,0 +1,21 @@
+package bytesring
+
+import "bufio"
+
+// NewReader returns a bufio.Reader over the ring with a buffer as large as the ring itself.
+//
+// Read returns whatever is buffered instead of waiting to fill the whole slice, so a
+// bufio.Reader never blocks on bytes it doesn't need to satisfy a call like ReadString.
+// Once the ring is closed and drained, the bufio.Reader returns the remaining buffered
+// bytes followed by io.EOF (or the error passed to CloseWithError).
+func NewReader(r *Ring) *bufio.Reader {
+	return bufio.NewReaderSize(r, r.Cap())
+}
+
+// NewWriter returns a bufio.Writer over the ring with a buffer as large as the ring itself.
+//
+// Every Flush blocks until the whole buffer fits in the ring, and fails with ErrClosed
+// if the ring gets closed in the meantime.
+func NewWriter(r *Ring) *bufio.Writer {
+	return bufio.NewWriterSize(r, r.Cap())
+}
@@ -0,0 +1,52 @@
+package bytesring
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestBufio(t *testing.T) {
+	r := New(16)
+
+	go func() {
+		w := NewWriter(r)
+		for _, line := range []string{"first\n", "a somewhat longer second line\n", "no newline"} {
+			if _, err := w.WriteString(line); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Error(err)
+		}
+		r.Close()
+	}()
+
+	br := NewReader(r)
+	for _, expected := range []string{"first\n", "a somewhat longer second line\n"} {
+		line, err := br.ReadString('\n')
+		if err != nil || line != expected {
+			t.Fatalf("Expected %q, got %q, %v.", expected, line, err)
+		}
+	}
+
+	// The last partial line must be returned intact, along with io.EOF.
+	line, err := br.ReadString('\n')
+	if err != io.EOF || line != "no newline" {
+		t.Fatalf("Expected partial line and io.EOF, got %q, %v.", line, err)
+	}
+}
+
+func TestCloseWithError(t *testing.T) {
+	r := New(4)
+	errBroken := errors.New("broken")
+
+	r.Write([]byte("ab"))
+	r.CloseWithError(errBroken)
+	r.Close()
+
+	b, err := io.ReadAll(r)
+	if string(b) != "ab" || !errors.Is(err, errBroken) {
+		t.Fatalf("Expected remaining bytes and the close error, got %q, %v.", b, err)
+	}
+}
@@ -0,0 +1,304 @@
+// Package bytesring provides a fixed-capacity byte ring buffer that implements the io
+// reading and writing interfaces with blocking backpressure.
+package bytesring
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrClosed is returned by writes to a closed Ring, and by reads from a Ring closed for reading.
+var ErrClosed = errors.New("bytesring: ring closed")
+
+// Ring is a fixed-capacity circular byte buffer. Writes block while the ring is full
+// and reads block while it is empty, so a Ring can connect a producer and a consumer
+// running at different paces.
+//
+// Positions are kept as monotonically increasing offsets, so the byte at offset o is
+// always stored at buf[o % len(buf)].
+type Ring struct {
+	rmu    sync.Mutex // Serializes readers
+	wmu    sync.Mutex // Serializes writers
+	mu     sync.Mutex // Guards the positions below
+	cond   *sync.Cond // Broadcast whenever data is written or read, or the ring is closed
+	buf    []byte
+	rpos   uint64 // Offset of the next byte to read
+	wpos   uint64 // Offset of the next byte to write
+	closed bool
+	rerr   error // Returned to readers once the ring is closed and empty
+	rdone  bool  // Whether the ring is closed for reading too
+}
+
+var (
+	_ io.ReadWriter = (*Ring)(nil)
+	_ io.ByteReader = (*Ring)(nil)
+	_ io.ByteWriter = (*Ring)(nil)
+	_ io.ReaderFrom = (*Ring)(nil)
+	_ io.WriterTo   = (*Ring)(nil)
+)
+
+// New creates a Ring that can hold size bytes.
+func New(size int) *Ring {
+	if size <= 0 {
+		return nil
+	}
+	r := &Ring{buf: make([]byte, size)}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+// Len returns the number of buffered bytes.
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int(r.wpos - r.rpos)
+}
+
+// Cap returns the capacity of the ring.
+func (r *Ring) Cap() int {
+	return len(r.buf)
+}
+
+// Must be called with mu held.
+func (r *Ring) free() int {
+	return len(r.buf) - int(r.wpos-r.rpos)
+}
+
+// Copy p into the ring at the writer position. p must fit into the free space.
+// Must be called with mu held.
+func (r *Ring) put(p []byte) {
+	i := int(r.wpos % uint64(len(r.buf)))
+	n := copy(r.buf[i:], p)
+	copy(r.buf, p[n:])
+	r.wpos += uint64(len(p))
+}
+
+// Copy buffered bytes into p from the reader position without consuming them.
+// Must be called with mu held.
+func (r *Ring) peek(p []byte) int {
+	if l := int(r.wpos - r.rpos); len(p) > l {
+		p = p[:l]
+	}
+	i := int(r.rpos % uint64(len(r.buf)))
+	n := copy(p, r.buf[i:])
+	n += copy(p[n:], r.buf)
+	return len(p)
+}
+
+// Write writes all of p into the ring, blocking while the ring is full.
+// If the ring is closed before all of p is written, Write returns ErrClosed along with
+// the number of bytes that made it in. Concurrent writes never interleave.
+func (r *Ring) Write(p []byte) (int, error) {
+	r.wmu.Lock()
+	defer r.wmu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.write(p)
+}
+
+// Must be called with wmu and mu held.
+func (r *Ring) write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		if r.closed {
+			return written, ErrClosed
+		}
+		free := r.free()
+		if free == 0 {
+			r.cond.Wait()
+			continue
+		}
+
+		n := min(free, len(p)-written)
+		r.put(p[written : written+n])
+		written += n
+		r.cond.Broadcast()
+	}
+	return written, nil
+}
+
+// WriteByte writes a single byte, blocking while the ring is full.
+func (r *Ring) WriteByte(c byte) error {
+	_, err := r.Write([]byte{c})
+	return err
+}
+
+// Read reads up to len(p) bytes from the ring, blocking until at least one byte is available.
+// Once the ring is closed and empty, Read returns io.EOF, or the error passed to CloseWithError.
+func (r *Ring) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	r.rmu.Lock()
+	defer r.rmu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for r.wpos == r.rpos && !r.closed {
+		r.cond.Wait()
+	}
+	if r.rdone {
+		return 0, ErrClosed
+	}
+	if r.wpos == r.rpos {
+		return 0, r.rerr
+	}
+
+	n := r.peek(p)
+	r.rpos += uint64(n)
+	r.cond.Broadcast()
+	return n, nil
+}
+
+// ReadByte reads a single byte, blocking until one is available.
+// Once the ring is closed and empty, ReadByte returns io.EOF.
+func (r *Ring) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := r.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// ReadFrom reads data from src directly into the free space of the ring until src
+// returns io.EOF, blocking while the ring is full. It holds off other writers for its
+// whole duration. If the ring is closed first, ReadFrom returns ErrClosed.
+func (r *Ring) ReadFrom(src io.Reader) (int64, error) {
+	r.wmu.Lock()
+	defer r.wmu.Unlock()
+
+	var total int64
+	for {
+		r.mu.Lock()
+		for !r.closed && r.free() == 0 {
+			r.cond.Wait()
+		}
+		if r.closed {
+			r.mu.Unlock()
+			return total, ErrClosed
+		}
+		// The free space may wrap around, so only expose the contiguous part of it.
+		// Readers never touch free space, so src can fill it without holding the lock.
+		i := int(r.wpos % uint64(len(r.buf)))
+		region := r.buf[i:min(len(r.buf), i+r.free())]
+		r.mu.Unlock()
+
+		n, err := src.Read(region)
+		total += int64(n)
+
+		r.mu.Lock()
+		// CloseRead discards everything, including what was just read.
+		if r.rdone {
+			r.mu.Unlock()
+			return total, ErrClosed
+		}
+		r.wpos += uint64(n)
+		r.cond.Broadcast()
+		r.mu.Unlock()
+
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// WriteTo writes buffered data directly from the ring to dst until the ring is closed
+// and empty, blocking while the ring is empty. It holds off other readers for its whole duration.
+// If the ring was closed with CloseWithError, WriteTo returns that error at the end.
+func (r *Ring) WriteTo(dst io.Writer) (int64, error) {
+	r.rmu.Lock()
+	defer r.rmu.Unlock()
+
+	var total int64
+	for {
+		r.mu.Lock()
+		for !r.closed && r.wpos == r.rpos {
+			r.cond.Wait()
+		}
+		if r.rdone {
+			r.mu.Unlock()
+			return total, ErrClosed
+		}
+		l := int(r.wpos - r.rpos)
+		if l == 0 {
+			err := r.rerr
+			r.mu.Unlock()
+			if err == io.EOF {
+				return total, nil
+			}
+			return total, err
+		}
+		// Writers never touch buffered data, so dst can consume it without holding the lock.
+		i := int(r.rpos % uint64(len(r.buf)))
+		region := r.buf[i:min(len(r.buf), i+l)]
+		r.mu.Unlock()
+
+		n, err := dst.Write(region)
+		total += int64(n)
+
+		r.mu.Lock()
+		// CloseRead already discarded the region, so the reader position stays where it put it.
+		if r.rdone {
+			r.mu.Unlock()
+			return total, ErrClosed
+		}
+		r.rpos += uint64(n)
+		r.cond.Broadcast()
+		r.mu.Unlock()
+
+		if err != nil {
+			return total, err
+		}
+		if n < len(region) {
+			return total, io.ErrShortWrite
+		}
+	}
+}
+
+// Close closes the ring for writing. Blocked and future writes fail with ErrClosed,
+// while reads return the remaining bytes and then io.EOF. Close is idempotent.
+func (r *Ring) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError closes the ring for writing like Close, but reads return err instead of
+// io.EOF once the remaining bytes have been read. A nil err is the same as io.EOF.
+// Only the first close of a ring has an effect.
+func (r *Ring) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.rerr = err
+	r.cond.Broadcast()
+	return nil
+}
+
+// CloseRead closes the ring from the reading side: buffered bytes are discarded,
+// and blocked and future reads and writes fail with ErrClosed. It is meant for
+// consumers that stop reading, so that producers don't block forever.
+func (r *Ring) CloseRead() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	r.rdone = true
+	r.rpos = r.wpos
+	r.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,131 @@
+package bytesring
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	r := New(7)
+
+	input := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(input)
+
+	go func() {
+		// Write in chunks that don't line up with the ring capacity.
+		for i := 0; i < len(input); i += 13 {
+			if _, err := r.Write(input[i:min(i+13, len(input))]); err != nil {
+				t.Error(err)
+			}
+		}
+		r.Close()
+	}()
+
+	output, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(input, output) {
+		t.Fatal("Bytes missing or reordered.")
+	}
+}
+
+func TestByteReadWrite(t *testing.T) {
+	r := New(2)
+
+	if err := r.WriteByte('a'); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteByte('b'); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 2 {
+		t.Fatalf("Expected 2 buffered bytes, got %d.", r.Len())
+	}
+	if c, err := r.ReadByte(); err != nil || c != 'a' {
+		t.Fatal("Expected to read 'a'.")
+	}
+	r.Close()
+
+	if c, err := r.ReadByte(); err != nil || c != 'b' {
+		t.Fatal("Expected to read remaining byte after Close.")
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Fatal("Expected io.EOF after draining a closed ring.")
+	}
+	if err := r.WriteByte('c'); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected write after close to fail.")
+	}
+}
+
+func TestReadFromWriteTo(t *testing.T) {
+	r := New(64)
+
+	input := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(input)
+
+	go func() {
+		if n, err := r.ReadFrom(bytes.NewReader(input)); err != nil || n != int64(len(input)) {
+			t.Errorf("ReadFrom returned %d, %v.", n, err)
+		}
+		r.Close()
+	}()
+
+	var output bytes.Buffer
+	if n, err := r.WriteTo(&output); err != nil || n != int64(len(input)) {
+		t.Fatalf("WriteTo returned %d, %v.", n, err)
+	}
+	if !bytes.Equal(input, output.Bytes()) {
+		t.Fatal("Bytes missing or reordered.")
+	}
+}
+
+func TestCloseRead(t *testing.T) {
+	r := New(4)
+	r.Write([]byte("ab"))
+	r.CloseRead()
+
+	if _, err := r.Read(make([]byte, 4)); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected read after CloseRead to fail.")
+	}
+	if _, err := r.Write([]byte("c")); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected write after CloseRead to fail.")
+	}
+}
+
+// stallWriter signals when a write starts, and finishes it once released.
+type stallWriter struct {
+	started, release chan struct{}
+}
+
+func (w stallWriter) Write(p []byte) (int, error) {
+	close(w.started)
+	<-w.release
+	return len(p), nil
+}
+
+func TestCloseReadDuringWriteTo(t *testing.T) {
+	r := New(4)
+	r.Write([]byte("ab"))
+
+	w := stallWriter{started: make(chan struct{}), release: make(chan struct{})}
+	result := make(chan error)
+	go func() {
+		_, err := r.WriteTo(w)
+		result <- err
+	}()
+
+	<-w.started
+	r.CloseRead()
+	close(w.release)
+
+	if err := <-result; !errors.Is(err, ErrClosed) {
+		t.Fatalf("Expected WriteTo to fail after CloseRead, got %v.", err)
+	}
+	if r.Len() != 0 {
+		t.Fatalf("Expected the ring to stay empty, got %d bytes.", r.Len())
+	}
+}
@@ -0,0 +1,65 @@
+package bytesring
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// ScanMsgs is a bufio.SplitFunc that returns every record in the format written by WriteMsg:
+// a 4 byte big-endian length prefix followed by that many bytes.
+func ScanMsgs(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) >= headerLen {
+		size := headerLen + int(binary.BigEndian.Uint32(data))
+		if len(data) >= size {
+			return size, data[headerLen:size:size], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	return 0, nil, nil
+}
+
+// Feeder reads length-prefixed frames, as written by WriteMsg, from a connection straight into
+// a Ring and hands them out as messages without copying them out of the ring.
+//
+// Frames can arrive split over any number of reads. While the ring is full the Feeder stops
+// reading from the connection, so that a consumer that falls behind pushes back on the sender,
+// like through TCP flow control. Frames can't be longer than the ring's capacity minus the prefix.
+type Feeder struct {
+	ring    *Ring
+	scanner *Scanner
+}
+
+// NewFeeder starts reading frames from src into a ring of the given size, until src returns
+// an error or the Feeder is closed.
+func NewFeeder(src io.Reader, size int) *Feeder {
+	r := New(size)
+	if r == nil {
+		return nil
+	}
+	go func() {
+		_, err := r.ReadFrom(src)
+		r.CloseWithError(err)
+	}()
+	return &Feeder{ring: r, scanner: NewScanner(r, ScanMsgs)}
+}
+
+// Next returns the next message, blocking until it has fully arrived. The message may point
+// into the ring, so it is only valid until the next call to Next. Once src is exhausted Next
+// returns io.EOF, or the error src failed with.
+func (f *Feeder) Next() ([]byte, error) {
+	if f.scanner.Scan() {
+		return f.scanner.Bytes(), nil
+	}
+	if err := f.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Close stops the Feeder, discarding buffered frames. A read from src that is in progress
+// only returns once src does, like when its connection is closed.
+func (f *Feeder) Close() error {
+	return f.ring.CloseRead()
+}
@@ -0,0 +1,53 @@
+package bytesring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestFeeder(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	var msgs [][]byte
+	for i := 0; i < 30; i++ {
+		msgs = append(msgs, bytes.Repeat([]byte{byte(i)}, i%10))
+	}
+	go func() {
+		var data []byte
+		for _, msg := range msgs {
+			data = binary.BigEndian.AppendUint32(data, uint32(len(msg)))
+			data = append(data, msg...)
+		}
+		// Write in pieces that split frames at arbitrary places.
+		for i := 0; i < len(data); i += 7 {
+			client.Write(data[i:min(i+7, len(data))])
+		}
+		client.Close()
+	}()
+
+	f := NewFeeder(server, 32)
+	for i, expected := range msgs {
+		msg, err := f.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(msg, expected) {
+			t.Fatalf("Message %d changed to %v.", i, msg)
+		}
+	}
+	if _, err := f.Next(); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v.", err)
+	}
+}
+
+func TestFeederTruncated(t *testing.T) {
+	src := bytes.NewReader([]byte{0, 0, 0, 5, 'a', 'b'})
+	f := NewFeeder(src, 16)
+	if _, err := f.Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v.", err)
+	}
+}
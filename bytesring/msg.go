@@ -0,0 +1,109 @@
+package bytesring
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ErrTooLarge is returned by WriteMsg for messages that can never fit in the ring.
+var ErrTooLarge = errors.New("bytesring: message too large")
+
+// Size of the length prefix of every message.
+const headerLen = 4
+
+// WriteMsg writes msg as a single length-prefixed record, blocking until there is
+// room for the whole record. The record becomes visible to readers all at once, so
+// messages of concurrent producers are never interleaved or torn.
+//
+// Messages should not be mixed with plain writes on the same ring, since readers
+// would not be able to tell where records begin.
+func (r *Ring) WriteMsg(msg []byte) error {
+	size := headerLen + len(msg)
+	if size > len(r.buf) || uint64(len(msg)) > 1<<32-1 {
+		return ErrTooLarge
+	}
+
+	var header [headerLen]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(msg)))
+
+	r.wmu.Lock()
+	defer r.wmu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for !r.closed && r.free() < size {
+		r.cond.Wait()
+	}
+	if r.closed {
+		return ErrClosed
+	}
+
+	r.put(header[:])
+	r.put(msg)
+	r.cond.Broadcast()
+	return nil
+}
+
+// ReadMsg reads the next record written by WriteMsg into a newly allocated slice,
+// blocking until one is available. Once the ring is closed and empty, ReadMsg returns
+// io.EOF, or io.ErrUnexpectedEOF if the ring ends in the middle of a record.
+func (r *Ring) ReadMsg() ([]byte, error) {
+	var msg []byte
+	_, err := r.readMsg(func(n int) []byte {
+		msg = make([]byte, n)
+		return msg
+	})
+	return msg, err
+}
+
+// ReadMsgInto reads the next record written by WriteMsg into buf and returns its length.
+// If buf is too small, ReadMsgInto returns the length of the record along with
+// io.ErrShortBuffer and leaves the record in the ring.
+func (r *Ring) ReadMsgInto(buf []byte) (int, error) {
+	return r.readMsg(func(n int) []byte {
+		if n > len(buf) {
+			return nil
+		}
+		return buf[:n]
+	})
+}
+
+// Read the next record into the slice returned by alloc for its length.
+// If alloc returns nil the record is left in the ring.
+func (r *Ring) readMsg(alloc func(n int) []byte) (int, error) {
+	r.rmu.Lock()
+	defer r.rmu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Wait for the header, and then for the rest of the record.
+	if err := r.await(headerLen); err != nil {
+		return 0, err
+	}
+	var header [headerLen]byte
+	r.peek(header[:])
+	size := headerLen + int(binary.BigEndian.Uint32(header[:]))
+	if size > len(r.buf) {
+		// Can't be a record written by WriteMsg.
+		return 0, ErrTooLarge
+	}
+	// The header is already buffered, so this can only fail with io.ErrUnexpectedEOF or ErrClosed.
+	if err := r.await(size); err != nil {
+		return 0, err
+	}
+
+	n := size - headerLen
+	msg := alloc(n)
+	if msg == nil && n > 0 {
+		return n, io.ErrShortBuffer
+	}
+
+	r.rpos += headerLen
+	r.peek(msg)
+	r.rpos += uint64(n)
+	r.cond.Broadcast()
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package bytesring
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestMsg(t *testing.T) {
+	r := New(64)
+	producers := 4
+	n := 200
+
+	// Variable-size messages that carry their producer and index.
+	message := func(p, i int) []byte {
+		return append([]byte(fmt.Sprintf("%d:%d:", p, i)), bytes.Repeat([]byte{'x'}, i%40)...)
+	}
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				if err := r.WriteMsg(message(p, i)); err != nil {
+					t.Error(err)
+				}
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		r.Close()
+	}()
+
+	next := make([]int, producers)
+	for {
+		msg, err := r.ReadMsg()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p, i int
+		if _, err := fmt.Sscanf(string(msg), "%d:%d:", &p, &i); err != nil {
+			t.Fatalf("Torn message %q.", msg)
+		}
+		if i != next[p] || !bytes.Equal(msg, message(p, i)) {
+			t.Fatalf("Torn or reordered message %q.", msg)
+		}
+		next[p]++
+	}
+	for p := range next {
+		if next[p] != n {
+			t.Fatalf("Expected %d messages from producer %d, got %d.", n, p, next[p])
+		}
+	}
+}
+
+func TestReadMsgInto(t *testing.T) {
+	r := New(16)
+
+	if err := r.WriteMsg(make([]byte, 13)); err != ErrTooLarge {
+		t.Fatal("Expected oversized message to be rejected.")
+	}
+	if err := r.WriteMsg([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := r.ReadMsgInto(make([]byte, 2))
+	if err != io.ErrShortBuffer || n != 5 {
+		t.Fatalf("Expected io.ErrShortBuffer and 5, got %v and %d.", err, n)
+	}
+
+	buf := make([]byte, 8)
+	n, err = r.ReadMsgInto(buf)
+	if err != nil || string(buf[:n]) != "hello" {
+		t.Fatalf("Unexpected message %q, %v.", buf[:n], err)
+	}
+}
@@ -0,0 +1,127 @@
+package bytesring
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Recorder is an io.Writer that never blocks and keeps only the last bytes written to it,
+// overwriting the oldest ones. It works as an in-memory flight recorder of recent output.
+type Recorder struct {
+	mu   sync.Mutex
+	buf  []byte
+	wpos uint64 // Total number of bytes written
+}
+
+// NewRecorder creates a Recorder that keeps the last size bytes.
+func NewRecorder(size int) *Recorder {
+	if size <= 0 {
+		return nil
+	}
+	return &Recorder{buf: make([]byte, size)}
+}
+
+// Write records p, dropping the oldest bytes if needed. It never fails.
+func (rec *Recorder) Write(p []byte) (int, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	n := len(p)
+	// Only the tail of p can survive anyway.
+	if len(p) > len(rec.buf) {
+		rec.wpos += uint64(len(p) - len(rec.buf))
+		p = p[len(p)-len(rec.buf):]
+	}
+
+	i := int(rec.wpos % uint64(len(rec.buf)))
+	c := copy(rec.buf[i:], p)
+	copy(rec.buf, p[c:])
+	rec.wpos += uint64(len(p))
+	return n, nil
+}
+
+// Bytes returns a copy of the recorded bytes, oldest first.
+func (rec *Recorder) Bytes() []byte {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.wpos < uint64(len(rec.buf)) {
+		return bytes.Clone(rec.buf[:rec.wpos])
+	}
+	i := int(rec.wpos % uint64(len(rec.buf)))
+	return append(bytes.Clone(rec.buf[i:]), rec.buf[:i]...)
+}
+
+// Dump writes the recorded bytes to w, oldest first.
+func (rec *Recorder) Dump(w io.Writer) error {
+	_, err := w.Write(rec.Bytes())
+	return err
+}
+
+// LineRecorder is an io.Writer that never blocks and keeps only the last lines written to it,
+// dropping the oldest ones. Lines are kept whole regardless of how writes split them.
+type LineRecorder struct {
+	mu      sync.Mutex
+	lines   [][]byte // Complete lines, including the newline
+	next    int      // Position of the next line in lines
+	full    bool     // Whether lines has wrapped around
+	partial []byte   // Last line, still waiting for its newline
+}
+
+// NewLineRecorder creates a LineRecorder that keeps the last n lines.
+func NewLineRecorder(n int) *LineRecorder {
+	if n <= 0 {
+		return nil
+	}
+	return &LineRecorder{lines: make([][]byte, n)}
+}
+
+// Write records p, dropping the oldest lines if needed. It never fails.
+func (rec *LineRecorder) Write(p []byte) (int, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			rec.partial = append(rec.partial, p...)
+			break
+		}
+
+		// Reuse the memory of the line being dropped.
+		line := append(rec.lines[rec.next][:0], rec.partial...)
+		rec.lines[rec.next] = append(line, p[:i+1]...)
+		rec.partial = rec.partial[:0]
+		p = p[i+1:]
+
+		rec.next++
+		if rec.next == len(rec.lines) {
+			rec.next = 0
+			rec.full = true
+		}
+	}
+	return n, nil
+}
+
+// Dump writes the recorded lines to w, oldest first, followed by the last line
+// if it hasn't been terminated yet.
+func (rec *LineRecorder) Dump(w io.Writer) error {
+	rec.mu.Lock()
+	var buf bytes.Buffer
+	if rec.full {
+		for _, line := range rec.lines[rec.next:] {
+			buf.Write(line)
+		}
+	}
+	for _, line := range rec.lines[:rec.next] {
+		buf.Write(line)
+	}
+	buf.Write(rec.partial)
+	rec.mu.Unlock()
+
+	// Don't hold the lock while writing to w, which might log back into the recorder.
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,46 @@
+package bytesring
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder(8)
+
+	rec.Write([]byte("abc"))
+	if string(rec.Bytes()) != "abc" {
+		t.Fatalf("Unexpected contents %q.", rec.Bytes())
+	}
+
+	rec.Write([]byte("defghij"))
+	if string(rec.Bytes()) != "cdefghij" {
+		t.Fatalf("Unexpected contents %q.", rec.Bytes())
+	}
+
+	rec.Write([]byte("0123456789"))
+	var buf bytes.Buffer
+	if err := rec.Dump(&buf); err != nil || buf.String() != "23456789" {
+		t.Fatalf("Unexpected dump %q, %v.", buf.String(), err)
+	}
+}
+
+func TestLineRecorder(t *testing.T) {
+	rec := NewLineRecorder(3)
+
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(rec, "line %d\n", i)
+	}
+	// A line split across writes must be kept whole.
+	rec.Write([]byte("li"))
+	rec.Write([]byte("ne 5\nline"))
+
+	var buf bytes.Buffer
+	if err := rec.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "line 3\nline 4\nline 5\nline"; buf.String() != expected {
+		t.Fatalf("Expected %q, got %q.", expected, buf.String())
+	}
+}
@@ -0,0 +1,115 @@
+package bytesring
+
+import (
+	"bufio"
+	"io"
+)
+
+// Scanner splits data arriving in a Ring into tokens with a bufio.SplitFunc, like bufio.Scanner,
+// but without copying the data out of the ring first. Scan blocks until a whole token is buffered.
+//
+// Data is only copied when a token wraps around the end of the ring, so tokens can't be longer
+// than the ring's capacity. The Scanner must be the only reader of the ring.
+type Scanner struct {
+	r       *Ring
+	split   bufio.SplitFunc
+	token   []byte
+	pending int    // Bytes consumed by the last token, committed on the next Scan
+	scratch []byte // Used to join data that wraps around
+	err     error
+	done    bool
+}
+
+// NewScanner returns a Scanner over r that splits tokens with split.
+func NewScanner(r *Ring, split bufio.SplitFunc) *Scanner {
+	return &Scanner{r: r, split: split}
+}
+
+// Scan advances to the next token, which is then available through Bytes or Text.
+// It returns false when the ring is closed and drained, or on error.
+func (s *Scanner) Scan() bool {
+	// The previous token is no longer needed, so release its space to writers.
+	s.r.Commit(s.pending)
+	s.pending = 0
+	s.token = nil
+	if s.done {
+		return false
+	}
+
+	atEOF := false
+	for {
+		data := s.buffered()
+
+		advance, token, err := s.split(data, atEOF)
+		if err == nil && advance < 0 {
+			err = bufio.ErrNegativeAdvance
+		}
+		if err == nil && advance > len(data) {
+			err = bufio.ErrAdvanceTooFar
+		}
+		if err != nil {
+			s.done = true
+			if err == bufio.ErrFinalToken {
+				s.token = token
+				return token != nil
+			}
+			s.err = err
+			return false
+		}
+		s.pending = advance
+		if token != nil {
+			s.token = token
+			return true
+		}
+		if advance > 0 {
+			s.r.Commit(advance)
+			s.pending = 0
+			continue
+		}
+		if atEOF {
+			s.done = true
+			return false
+		}
+
+		// The split function needs more data than is buffered.
+		switch err := s.r.Wait(len(data) + 1); err {
+		case nil:
+		case ErrTooLarge:
+			s.done = true
+			s.err = bufio.ErrTooLong
+			return false
+		case io.EOF, io.ErrUnexpectedEOF:
+			atEOF = true
+		default:
+			s.done = true
+			s.err = err
+			return false
+		}
+	}
+}
+
+// Return all buffered data as a single slice, joining it only if it wraps around.
+func (s *Scanner) buffered() []byte {
+	a, b := s.r.ReadSlices(s.r.Cap())
+	if len(b) == 0 {
+		return a
+	}
+	s.scratch = append(append(s.scratch[:0], a...), b...)
+	return s.scratch
+}
+
+// Bytes returns the current token. It may point into the ring, so it is only valid until
+// the next call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.token
+}
+
+// Text returns a copy of the current token as a string.
+func (s *Scanner) Text() string {
+	return string(s.token)
+}
+
+// Err returns the first error encountered, except for the ring being closed with io.EOF.
+func (s *Scanner) Err() error {
+	return s.err
+}
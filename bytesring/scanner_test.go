@@ -0,0 +1,51 @@
+package bytesring
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	r := New(16)
+
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, strings.Repeat("x", i%12))
+	}
+
+	go func() {
+		// Write in pieces that split lines at arbitrary places.
+		data := strings.Join(lines, "\n")
+		for i := 0; i < len(data); i += 5 {
+			r.Write([]byte(data[i:min(i+5, len(data))]))
+		}
+		r.Close()
+	}()
+
+	s := NewScanner(r, bufio.ScanLines)
+	i := 0
+	for s.Scan() {
+		if s.Text() != lines[i] {
+			t.Fatalf("Expected %q, got %q.", lines[i], s.Text())
+		}
+		i++
+	}
+	if s.Err() != nil {
+		t.Fatal(s.Err())
+	}
+	if i != len(lines) {
+		t.Fatalf("Expected %d lines, got %d.", len(lines), i)
+	}
+}
+
+func TestScannerTooLong(t *testing.T) {
+	r := New(4)
+	go r.Write([]byte("abcdef\n"))
+
+	s := NewScanner(r, bufio.ScanLines)
+	if s.Scan() || !errors.Is(s.Err(), bufio.ErrTooLong) {
+		t.Fatalf("Expected bufio.ErrTooLong, got %v.", s.Err())
+	}
+}
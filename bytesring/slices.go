@@ -0,0 +1,83 @@
+package bytesring
+
+import "io"
+
+// ReadSlices returns views of up to n buffered bytes without consuming them or waiting for more.
+// Since buffered data may wrap around the end of the ring, it comes in up to two slices:
+// b is only non-empty if a reaches the end of the underlying buffer.
+//
+// The views stay valid until they are released with Commit, because writers never
+// overwrite uncommitted data. They must not be modified, and ReadSlices and Commit
+// must not be mixed with concurrent reads by other goroutines.
+func (r *Ring) ReadSlices(n int) (a, b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rdone {
+		return nil, nil
+	}
+	if l := int(r.wpos - r.rpos); n > l {
+		n = l
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	i := int(r.rpos % uint64(len(r.buf)))
+	if i+n <= len(r.buf) {
+		return r.buf[i : i+n], nil
+	}
+	return r.buf[i:], r.buf[:i+n-len(r.buf)]
+}
+
+// Commit consumes n bytes, releasing the space they occupy to writers.
+// It panics if fewer than n bytes are buffered.
+func (r *Ring) Commit(n int) {
+	if n <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rdone {
+		return
+	}
+	if n > int(r.wpos-r.rpos) {
+		panic("bytesring: commit beyond buffered data")
+	}
+	r.rpos += uint64(n)
+	r.cond.Broadcast()
+}
+
+// Wait blocks until at least n bytes are buffered, so that ReadSlices can return them.
+// If the ring gets closed first, Wait returns io.EOF (or the error passed to CloseWithError)
+// if the ring is empty, and io.ErrUnexpectedEOF otherwise. It returns ErrTooLarge if n
+// exceeds the capacity of the ring.
+func (r *Ring) Wait(n int) error {
+	if n > len(r.buf) {
+		return ErrTooLarge
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.await(n)
+}
+
+// Must be called with mu held.
+func (r *Ring) await(n int) error {
+	for !r.closed && int(r.wpos-r.rpos) < n {
+		r.cond.Wait()
+	}
+	if r.rdone {
+		return ErrClosed
+	}
+	if l := int(r.wpos - r.rpos); l < n {
+		if l == 0 {
+			return r.rerr
+		}
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package bytesring
+
+import (
+	"io"
+	"testing"
+)
+
+func TestReadSlices(t *testing.T) {
+	r := New(8)
+
+	if a, b := r.ReadSlices(4); len(a) != 0 || len(b) != 0 {
+		t.Fatal("Expected no views on an empty ring.")
+	}
+
+	// Move positions close to the end of the buffer, so that data wraps around.
+	r.Write([]byte("123456"))
+	r.Commit(6)
+	r.Write([]byte("abcdef"))
+
+	a, b := r.ReadSlices(5)
+	if string(a) != "ab" || string(b) != "cde" {
+		t.Fatalf("Unexpected views %q, %q.", a, b)
+	}
+	if r.Len() != 6 {
+		t.Fatal("ReadSlices must not consume data.")
+	}
+
+	r.Commit(5)
+	if a, b := r.ReadSlices(10); string(a) != "f" || len(b) != 0 {
+		t.Fatalf("Unexpected views %q, %q.", a, b)
+	}
+}
+
+func TestWait(t *testing.T) {
+	r := New(4)
+
+	go r.Write([]byte("abc"))
+	if err := r.Wait(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Wait(5); err != ErrTooLarge {
+		t.Fatal("Expected ErrTooLarge.")
+	}
+
+	r.Close()
+	if err := r.Wait(4); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v.", err)
+	}
+	r.Commit(3)
+	if err := r.Wait(1); err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v.", err)
+	}
+}
@@ -1,29 +1,71 @@
 package cirque
 
 import (
+	"container/list"
 	"container/ring"
+	"context"
+	"github.com/denis-ismailaj/cirque/clock"
 	log "github.com/sirupsen/logrus"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
 // Cirque is a FIFO queue backed by a circular list (Ring from container/ring) that enables
 // independent reads and writes.
 type Cirque[T any] struct {
-	writeHead *ring.Ring // Writer head position pointer
-	readHead  *ring.Ring // Reader head position pointer
-	readMu    sync.Mutex // Mutex lock for reads only
-	len       int        // Number of items in queue
-	cap       int        // Capacity of queue
+	writeHead *ring.Ring                    // Writer head position pointer
+	readHead  *ring.Ring                    // Reader head position pointer
+	readMu    sync.Mutex                    // Mutex lock for reads only
+	writeMu   sync.Mutex                    // Mutex lock for writes only
+	len       atomic.Int64                  // Number of items in queue
+	cap       int                           // Capacity of queue
+	stats     stats                         // Cumulative counters
+	ready     chan struct{}                 // Signalled when items are enqueued
+	arrived   atomic.Pointer[chan struct{}] // Closed when items are enqueued, created while watched for
+	done      chan struct{}                 // Closed when the queue is closed
+	closeOnce sync.Once
+	closed    bool          // Whether the queue is closed, guarded by writeMu
+	waitMu    sync.Mutex    // Mutex lock for waiters only
+	waiters   list.List     // Goroutines waiting in DequeueWait, first in line first
+	admit     func(T) bool  // Decides whether an item gets enqueued, if set by an option
+	onExpire  func(T)       // Called with expired items, if set by an option
+	onEvict   func(T)       // Called with evicted items, if set by an option
+	sizer     func(T) int64 // Size of an item in bytes, if set by an option
+	stamp     bool          // Whether to record when items are enqueued, if set by an option
+	expiring  atomic.Bool   // Whether any item was enqueued with a TTL
+	writeSeq  uint64        // Sequence number of the last enqueued item, guarded by writeMu
+	readSeq   uint64        // Sequence number of the last dequeued item, guarded by readMu
+
+	sweepInterval time.Duration // How often to sweep expired items, if set by an option
+	clock         clock.Clock   // Clock for expiry, enqueue times, dedup windows and bursts
+	growths       []Growth      // Most recent grows, guarded by readMu
+
+	bound bound[T] // Limit on the items in the queue, if set by an option
+
+	reserveMu    sync.Mutex      // Mutex lock for reservations only
+	reservations map[Token][]any // Items of uncommitted reservations as stored in the ring, created on first use
+	finalized    chan struct{}   // Closed once a reservation is finalized, created while waiting for one
+	lastToken    Token
 }
 
+// Option configures a Cirque.
+type Option[T any] func(*Cirque[T])
+
 // New creates a Cirque of initial size n with items of type T.
-func New[T any](n int) *Cirque[T] {
+func New[T any](n int, opts ...Option[T]) *Cirque[T] {
 	if n <= 0 {
 		return nil
 	}
 	cq := new(Cirque[T])
+	cq.ready = make(chan struct{}, 1)
+	cq.done = make(chan struct{})
+	cq.clock = clock.Real
+
+	for _, opt := range opts {
+		opt(cq)
+	}
 
 	// Saving capacity in the struct itself.
 	// This can be calculated by calling Ring.Len(), but that has O(n) complexity.
@@ -34,13 +76,25 @@ func New[T any](n int) *Cirque[T] {
 	cq.readHead = ring.New(n)
 	cq.writeHead = cq.readHead
 
+	if cq.sweepInterval > 0 {
+		go cq.sweepEvery(cq.sweepInterval)
+	}
+
 	return cq
 }
 
+// Cap returns the current capacity of the queue, which grows as needed.
+func (cq *Cirque[T]) Cap() int {
+	// Capacity only changes while growing, which holds the read lock.
+	cq.readMu.Lock()
+	defer cq.readMu.Unlock()
+	return cq.cap
+}
+
 // Len returns the number of items currently in the queue.
 // Because this is updated on every operation, this method offers O(1) complexity.
 func (cq *Cirque[T]) Len() int {
-	return cq.len
+	return int(cq.len.Load())
 }
 
 func (cq *Cirque[T]) loadHead(head **ring.Ring) *ring.Ring {
@@ -48,34 +102,27 @@ func (cq *Cirque[T]) loadHead(head **ring.Ring) *ring.Ring {
 }
 
 func (cq *Cirque[T]) getReaderHead() *ring.Ring {
+	reach(LoadReadHead)
 	return cq.loadHead(&cq.readHead)
 }
 
 func (cq *Cirque[T]) getWriterHead() *ring.Ring {
+	reach(LoadWriteHead)
 	return cq.loadHead(&cq.writeHead)
 }
 
-func (cq *Cirque[T]) moveHeadForward(head **ring.Ring) {
-	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(head)), unsafe.Pointer((*head).Next()))
-}
-
-func (cq *Cirque[T]) moveWriterHeadForward() {
-	cq.moveHeadForward(&cq.writeHead)
-}
-
-func (cq *Cirque[T]) moveReaderHeadForward() {
-	cq.moveHeadForward(&cq.readHead)
+func (cq *Cirque[T]) storeHead(head **ring.Ring, pos *ring.Ring) {
+	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(head)), unsafe.Pointer(pos))
 }
 
-// Write to the current position
-func (cq *Cirque[T]) write(item T) {
-	h := cq.getWriterHead()
-	h.Value = item
+func (cq *Cirque[T]) setWriterHead(pos *ring.Ring) {
+	reach(StoreWriteHead)
+	cq.storeHead(&cq.writeHead, pos)
 }
 
-// Read from current position.
-func (cq *Cirque[T]) read() T {
-	return cq.getReaderHead().Value.(T)
+func (cq *Cirque[T]) setReaderHead(pos *ring.Ring) {
+	reach(StoreReadHead)
+	cq.storeHead(&cq.readHead, pos)
 }
 
 // Raise the capacity of the Cirque by modifying the underlying ring.
@@ -84,6 +131,13 @@ func (cq *Cirque[T]) grow(min int) {
 		log.Warningf("Tried to call grow with invalid min: %d.", min)
 		return
 	}
+	// Growing blocks the writer until ongoing reads are done, so account for it.
+	start := time.Now()
+	defer func() {
+		cq.stats.grows.Add(1)
+		cq.stats.growTime.Add(int64(time.Since(start)))
+	}()
+
 	cq.readMu.Lock()
 	defer cq.readMu.Unlock()
 
@@ -95,61 +149,316 @@ func (cq *Cirque[T]) grow(min int) {
 
 	// Update capacity
 	cq.cap += min
+	cq.recordGrowth(start)
 
 	log.Debugf("Grew capacity to %d.", cq.cap)
 }
 
-// Enqueue adds the input elements to the queue
+// Enqueue adds the input elements to the queue.
+// It is safe to call Enqueue from multiple goroutines. Elements of a single call are
+// kept together and in order, while the order between concurrent calls is unspecified.
+//
+// On a bounded queue, Enqueue blocks until the whole batch fits, and rejects batches that never can.
+// On a closed queue, the batch is rejected too. Rejected batches are counted in Stats.
+// Use EnqueueWait or TryEnqueue to find out whether the batch was written.
 func (cq *Cirque[T]) Enqueue(elements ...T) {
+	if cq.bound.limit > 0 {
+		cq.enqueueBounded(elements, nil, time.Time{})
+		return
+	}
+	if _, err := cq.enqueue(elements, nil, time.Time{}); err != nil {
+		cq.reject(elements, err)
+	}
+}
+
+// Enqueue elements on a bounded queue, rejecting them if they can never fit.
+func (cq *Cirque[T]) enqueueBounded(elements []T, seqs []uint64, expires time.Time) {
+	if err := cq.enqueueWait(context.Background(), elements, seqs, expires); err != nil {
+		cq.reject(elements, err)
+	}
+}
+
+// Account for elements that couldn't be enqueued because of err.
+func (cq *Cirque[T]) reject(elements []T, err error) {
+	log.Warningf("Rejected %d items: %v.", len(elements), err)
+	cq.stats.rejected.Add(uint64(len(elements)))
+}
+
+// Enqueue the elements, filling seqs with their sequence numbers unless it's nil,
+// and return the ones that were admitted. Unless expires is zero, the elements expire at that time.
+// If the queue is closed, none of them are and ErrClosed is returned.
+func (cq *Cirque[T]) enqueue(elements []T, seqs []uint64, expires time.Time) ([]T, error) {
 	log.Debugf("Enqueuing %d items.", len(elements))
+
+	// Writers are serialized among themselves, but they never take the read lock
+	// unless the queue has to grow, so a single writer still doesn't disturb reads.
+	cq.writeMu.Lock()
+	defer cq.writeMu.Unlock()
+
+	return cq.enqueueLocked(elements, seqs, expires)
+}
+
+// Enqueue the elements like enqueue.
+// Must be called with writeMu held.
+func (cq *Cirque[T]) enqueueLocked(elements []T, seqs []uint64, expires time.Time) ([]T, error) {
+	if cq.closed {
+		return nil, ErrClosed
+	}
+
+	if cq.admit != nil {
+		elements = cq.filter(elements, seqs)
+	} else {
+		for i := range seqs {
+			seqs[i] = cq.writeSeq + uint64(i) + 1
+		}
+	}
+	if len(elements) == 0 {
+		return nil, nil
+	}
+	cq.writeSeq += uint64(len(elements))
+
+	// One slot always stays empty, so that a full queue can be told apart from an empty one.
+	// Readers only update the length after moving their head, so this never overestimates free space.
+	if free := cq.cap - 1 - cq.Len(); free < len(elements) {
+		// grow is a blocking call here, and since we hold the write lock
+		// no other writer can move the writer head in the meantime.
+		minSize := cq.cap + len(elements)
+		cq.grow(minSize)
+	}
+
+	var enqueued time.Time
+	if cq.stamp {
+		enqueued = cq.clock.Now()
+	}
+
+	// Write data in consecutive positions, without publishing them yet.
+	h := cq.getWriterHead()
 	for _, item := range elements {
-		// If the writer head is next to the reader head the queue is full.
-		if cq.getWriterHead().Next() == cq.getReaderHead() {
-			// grow is a blocking call here, and since we assume a single writer
-			// this is safe to do without a lock for writes.
-			minSize := cq.cap + len(elements)
-			cq.grow(minSize)
+		if expires.IsZero() && enqueued.IsZero() {
+			h.Value = item
+		} else {
+			h.Value = expiring[T]{item: item, expires: expires, enqueued: enqueued}
 		}
+		h = h.Next()
+	}
+
+	// Update length before publishing, so that it never drops below zero.
+	cq.len.Add(int64(len(elements)))
+	cq.stats.enqueued.Add(uint64(len(elements)))
+
+	// Move writer head past the whole batch at once.
+	cq.setWriterHead(h)
 
-		// Write data in the current position.
-		cq.write(item)
+	cq.signal()
+	return elements, nil
+}
 
-		// Update length
-		cq.len++
+// Return the elements that are admitted into the queue, without modifying the input.
+// If seqs isn't nil, admitted elements get their sequence numbers in it and rejected ones get 0.
+// Must be called with writeMu held.
+func (cq *Cirque[T]) filter(elements []T, seqs []uint64) []T {
+	admitted := make([]T, 0, len(elements))
+	for i, item := range elements {
+		if cq.admit(item) {
+			admitted = append(admitted, item)
+			if seqs != nil {
+				seqs[i] = cq.writeSeq + uint64(len(admitted))
+			}
+		}
+	}
+	cq.stats.rejected.Add(uint64(len(elements) - len(admitted)))
+	return admitted
+}
 
-		// Move writer head to the next position.
-		cq.moveWriterHeadForward()
+// Wake up one goroutine waiting for items in DequeueWait, if any, and every goroutine watching
+// for arrivals.
+func (cq *Cirque[T]) signal() {
+	select {
+	case cq.ready <- struct{}{}:
+	default:
+	}
+	if cq.arrived.Load() != nil {
+		if ch := cq.arrived.Swap(nil); ch != nil {
+			close(*ch)
+		}
+	}
+}
+
+// Return a channel that is closed once items are next enqueued. Unlike the ready signal, which
+// only one waiter gets, it is shared by every goroutine watching, so watching steals nothing from
+// DequeueWait. Items already in the queue don't close it, so watchers must get it before checking.
+func (cq *Cirque[T]) arrival() <-chan struct{} {
+	for {
+		if ch := cq.arrived.Load(); ch != nil {
+			return *ch
+		}
+		ch := make(chan struct{})
+		if cq.arrived.CompareAndSwap(nil, &ch) {
+			return ch
+		}
+	}
+}
+
+// Close marks the queue as closed. Once it returns, writes fail with ErrClosed, or are rejected
+// by Enqueue, while items already in the queue can still be dequeued. Goroutines waiting for room
+// are released with ErrClosed right away, and goroutines waiting for items once the queue is empty.
+// Close is idempotent.
+func (cq *Cirque[T]) Close() {
+	cq.closeOnce.Do(func() {
+		log.Debugf("Closing queue.")
+
+		// Taking the write lock lets writes in progress land first, so none of them ends up after Close.
+		cq.writeMu.Lock()
+		cq.closed = true
+		cq.writeMu.Unlock()
+
+		close(cq.done)
+	})
+}
+
+// CloseAndDrain closes the queue and returns every item left in it, as needed when tearing down
+// a connection that a queue feeds. Writes in progress land before the items are taken, and items
+// of reservations still open are awaited, in case they get rolled back. If ctx is done before
+// every reservation is finalized, it returns the items left along with the context's error.
+func (cq *Cirque[T]) CloseAndDrain(ctx context.Context) ([]T, error) {
+	cq.Close()
+	err := cq.awaitReservations(ctx)
+
+	var result []T
+	for cq.Len() > 0 {
+		result = append(result, cq.Dequeue(cq.Len())...)
+	}
+
+	log.Debugf("Drained %d items after closing.", len(result))
+	return result, err
+}
+
+// Whether the queue was closed.
+func (cq *Cirque[T]) isClosed() bool {
+	select {
+	case <-cq.done:
+		return true
+	default:
+		return false
 	}
 }
 
 // Dequeue returns a maximum of n items from the queue.
 func (cq *Cirque[T]) Dequeue(n int) []T {
+	// Temporary slice to populate with results
+	var result []T
+
+	cq.take(n, func(_ uint64, item T, _ any) {
+		result = append(result, item)
+	})
+
+	log.Debugf("Dequeuing %d items.", len(result))
+	return result
+}
+
+// Discard removes a maximum of n items from the queue without returning them,
+// and returns the number of items removed.
+func (cq *Cirque[T]) Discard(n int) int {
+	discarded := cq.take(n, nil)
+
+	log.Debugf("Discarded %d items.", discarded)
+	return discarded
+}
+
+// Move the reader head past a maximum of n items, passing each one along with its sequence
+// number and the value it was stored as in the ring to fn unless it's nil.
+// Expired items are skipped and don't count towards n.
+func (cq *Cirque[T]) take(n int, fn func(uint64, T, any)) int {
 	if n <= 0 {
-		return nil
+		return 0
 	}
 
-	// Temporary slice to populate with results
-	var result []T
+	var expired []T
+	defer func() {
+		// Report expired items without holding the lock.
+		cq.reportExpired(expired)
+	}()
 
 	cq.readMu.Lock()
 	defer cq.readMu.Unlock()
 
-	for i := 0; i < n; i++ {
-		// If reader head is in the same place as writer head no data is available to read.
-		if cq.getReaderHead() == cq.getWriterHead() {
-			return result
+	// Everything before this writer head position has already been published.
+	end := cq.getWriterHead()
+
+	// If reader head is in the same place as writer head no data is available to read.
+	h := cq.getReaderHead()
+	now := cq.now()
+	taken, passed := 0, 0
+	var cost int64
+	for ; taken < n && h != end; h = h.Next() {
+		item, expires := unbox[T](h.Value)
+		passed++
+		if cq.bound.limit > 0 {
+			cost += cq.bound.cost(item)
+		}
+		if !expires.IsZero() && !now.Before(expires) {
+			expired = append(expired, item)
+			continue
 		}
 
 		// Dequeue from current position.
-		result = append(result, cq.read())
+		if fn != nil {
+			fn(cq.readSeq+uint64(passed), item, h.Value)
+		}
+		taken++
+	}
+	if passed == 0 {
+		return 0
+	}
+
+	// Move reader head past the whole batch at once.
+	cq.setReaderHead(h)
 
-		// Update length
-		cq.len--
+	// Update length
+	cq.len.Add(-int64(passed))
+	cq.stats.dequeued.Add(uint64(taken))
+	cq.stats.expired.Add(uint64(len(expired)))
+	cq.readSeq += uint64(passed)
 
-		// Move reader head to the next position.
-		cq.moveReaderHeadForward()
+	if cq.bound.limit > 0 {
+		cq.bound.release(cost)
 	}
+	return taken
+}
 
-	log.Debugf("Dequeuing %d items.", len(result))
+// Snapshot returns a copy of the items currently in the queue, in order, without removing them.
+// Items enqueued while the snapshot is being taken are not included.
+func (cq *Cirque[T]) Snapshot() []T {
+	result := cq.peek(-1)
+
+	log.Debugf("Took snapshot of %d items.", len(result))
+	return result
+}
+
+// Peek returns a copy of a maximum of n items from the front of the queue, without removing them.
+func (cq *Cirque[T]) Peek(n int) []T {
+	if n <= 0 {
+		return nil
+	}
+	return cq.peek(n)
+}
+
+// Copy a maximum of n items from the front of the queue, or all of them if n is negative.
+func (cq *Cirque[T]) peek(n int) []T {
+	// Holding the read lock keeps readers from moving and the ring from growing underneath us.
+	cq.readMu.Lock()
+	defer cq.readMu.Unlock()
+
+	// Everything before this writer head position has already been published.
+	end := cq.getWriterHead()
+
+	var result []T
+	now := cq.now()
+	for h := cq.getReaderHead(); h != end && len(result) != n; h = h.Next() {
+		item, expires := unbox[T](h.Value)
+		if expires.IsZero() || now.Before(expires) {
+			result = append(result, item)
+		}
+	}
 	return result
 }
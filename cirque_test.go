@@ -1,6 +1,13 @@
 package cirque
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestEnqueueDequeue(t *testing.T) {
 	initialSize := 50
@@ -25,3 +32,166 @@ func TestEnqueueDequeue(t *testing.T) {
 	// Test for panic when dequeuing when empty.
 	cq.Dequeue(50)
 }
+
+func TestConcurrentEnqueue(t *testing.T) {
+	cq := New[[2]int](10)
+
+	producers := 8
+	n := 1000
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				cq.Enqueue([2]int{p, i})
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	if cq.Len() != producers*n {
+		t.Fatalf("Expected %d items, got %d.", producers*n, cq.Len())
+	}
+
+	// Items of each producer must come out in the order they were written.
+	next := make([]int, producers)
+	for _, item := range cq.Dequeue(producers * n) {
+		if item[1] != next[item[0]] {
+			t.Fatal("Items missing or reordered.")
+		}
+		next[item[0]]++
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	cq := New[int](2)
+
+	if len(cq.Snapshot()) != 0 {
+		t.Fatal("Expected empty snapshot.")
+	}
+
+	cq.Enqueue(1, 2, 3, 4)
+	cq.Dequeue(1)
+
+	s := cq.Snapshot()
+	if len(s) != 3 || s[0] != 2 || s[2] != 4 {
+		t.Fatalf("Unexpected snapshot %v.", s)
+	}
+
+	// Taking a snapshot must not consume anything.
+	if cq.Len() != 3 || cq.Dequeue(1)[0] != 2 {
+		t.Fatal("Snapshot consumed items.")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	cq := New[int](2)
+	cq.Enqueue(1, 2, 3)
+
+	if p := cq.Peek(2); len(p) != 2 || p[0] != 1 || p[1] != 2 {
+		t.Fatalf("Unexpected items %v.", p)
+	}
+	if len(cq.Peek(10)) != 3 || cq.Len() != 3 {
+		t.Fatal("Peek consumed items.")
+	}
+	if cq.Cap() < 4 {
+		t.Fatalf("Expected the queue to have grown, got capacity %d.", cq.Cap())
+	}
+}
+
+func TestConcurrentEnqueueDequeue(t *testing.T) {
+	cq := New[int](4)
+	n := 10000
+
+	go func() {
+		for i := 0; i < n; i += 10 {
+			cq.Enqueue(i, i+1, i+2, i+3, i+4, i+5, i+6, i+7, i+8, i+9)
+		}
+	}()
+
+	for expected := 0; expected < n; {
+		for _, item := range cq.Dequeue(7) {
+			if item != expected {
+				t.Fatal("Items missing or reordered.")
+			}
+			expected++
+		}
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2, 3, 4, 5)
+
+	if cq.Discard(0) != 0 || cq.Discard(3) != 3 {
+		t.Fatal("Unexpected number of discarded items.")
+	}
+	if cq.Len() != 2 || cq.Dequeue(1)[0] != 4 {
+		t.Fatal("Discard didn't skip the oldest items.")
+	}
+	if cq.Discard(10) != 1 || cq.Len() != 0 {
+		t.Fatal("Expected Discard to stop at the end of the queue.")
+	}
+}
+
+func TestClose(t *testing.T) {
+	cq := New(4, WithBound[int](2))
+	cq.Enqueue(1, 2)
+
+	blocked := make(chan error)
+	go func() {
+		blocked <- cq.EnqueueWait(context.Background(), 3)
+	}()
+
+	cq.Close()
+	if err := <-blocked; !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected a blocked writer to be released.")
+	}
+	if err := cq.TryEnqueue(3); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected writes after close to fail.")
+	}
+	cq.Enqueue(3)
+	if cq.Stats().Rejected != 1 {
+		t.Fatal("Expected Enqueue after close to be rejected.")
+	}
+
+	// Remaining items can still be read.
+	if items, err := cq.DequeueWait(context.Background(), 10); err != nil || !slices.Equal(items, []int{1, 2}) {
+		t.Fatalf("Expected the remaining items, got %v.", items)
+	}
+	if _, err := cq.DequeueWait(context.Background(), 10); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected reads to fail once the queue is drained.")
+	}
+}
+
+func TestCloseAndDrain(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2, 3)
+	_, token := cq.Reserve(1)
+
+	// The reservation is rolled back while the drain waits for it.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cq.Rollback(token)
+	}()
+	items, err := cq.CloseAndDrain(context.Background())
+	if err != nil || !slices.Equal(items, []int{1, 2, 3}) {
+		t.Fatalf("Expected every item back, got %v.", items)
+	}
+	if err := cq.TryEnqueue(4); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected the queue to be closed.")
+	}
+
+	// Reservations that stay open are given up on once ctx is done.
+	cq = New[int](4)
+	cq.Enqueue(1, 2)
+	cq.Reserve(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	items, err = cq.CloseAndDrain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !slices.Equal(items, []int{2}) {
+		t.Fatalf("Expected the items left along with the error, got %v.", items)
+	}
+}
@@ -0,0 +1,87 @@
+package cirquehttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/codec"
+	log "github.com/sirupsen/logrus"
+)
+
+// Producer returns an http.Handler that accepts WebSocket connections from remote producers and
+// enqueues every message they send into q, decoded with c. Items are enqueued with EnqueueWait,
+// so while a bounded queue is full the handler stops reading, and TCP flow control holds back
+// the producer. Messages that can't be decoded close the connection.
+func Producer[T any](q *cirque.Cirque[T], c codec.Codec[T]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				if err != io.EOF {
+					log.Debugf("Producer connection ended: %v.", err)
+				}
+				return
+			}
+			item, err := c.Decode(data)
+			if err != nil {
+				conn.closeWith(closeInvalidData, "can't decode item")
+				return
+			}
+			if err := q.EnqueueWait(r.Context(), item); err != nil {
+				conn.closeWith(closePolicy, err.Error())
+				return
+			}
+		}
+	})
+}
+
+// Consumer returns an http.Handler that accepts WebSocket connections from remote consumers and
+// sends them items dequeued from q, encoded with c, one message per item. An item is only dequeued
+// once the previous one was written, so a slow consumer leaves items in q for others, and only the
+// item being sent when a connection breaks is lost. Once q is closed and empty, the connection is closed.
+func Consumer[T any](q *cirque.Cirque[T], c codec.Codec[T]) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Keep reading, to answer pings and to notice the consumer going away.
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		go func() {
+			defer cancel()
+			for {
+				if _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for {
+			// Stop once q is closed and empty, or the consumer went away.
+			items, err := q.DequeueWait(ctx, 1)
+			if err != nil {
+				return
+			}
+			data, err := c.Encode(items[0])
+			if err != nil {
+				log.Warningf("Failed to encode item for consumer: %v.", err)
+				continue
+			}
+			if err := conn.WriteMessage(data); err != nil {
+				log.Warningf("Lost an item sending it to a consumer: %v.", err)
+				return
+			}
+		}
+	})
+}
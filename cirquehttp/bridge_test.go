@@ -0,0 +1,87 @@
+package cirquehttp
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+func dial(t *testing.T, server *httptest.Server) *Conn {
+	conn, err := Dial(context.Background(), strings.Replace(server.URL, "http", "ws", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestProducer(t *testing.T) {
+	q := cirque.New[int](4, cirque.WithBound[int](2))
+	server := httptest.NewServer(Producer(q, codec.JSON[int]()))
+	defer server.Close()
+
+	conn := dial(t, server)
+	defer conn.Close()
+	for _, msg := range []string{"1", "2", "3"} {
+		if err := conn.WriteMessage([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The third item waits for room in the queue.
+	deadline := time.Now().Add(time.Second)
+	for q.Len() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected items to be enqueued.")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if q.Len() != 2 {
+		t.Fatalf("Expected the bound to hold back the producer, got %d items.", q.Len())
+	}
+
+	items, err := q.DequeueWait(context.Background(), 2)
+	if err != nil || len(items) != 2 || items[0] != 1 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if items, _ := q.DequeueWait(context.Background(), 1); items[0] != 3 {
+		t.Fatalf("Unexpected item %v.", items)
+	}
+
+	if err := conn.WriteMessage([]byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ReadMessage(); err != io.EOF {
+		t.Fatal("Expected undecodable items to close the connection.")
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	q := cirque.New[int](4)
+	server := httptest.NewServer(Consumer(q, codec.JSON[int]()))
+	defer server.Close()
+
+	q.Enqueue(1, 2)
+	q.Close()
+
+	conn := dial(t, server)
+	defer conn.Close()
+	for _, expected := range []string{"1", "2"} {
+		msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg) != expected {
+			t.Fatalf("Expected %s, got %s.", expected, msg)
+		}
+	}
+	if _, err := conn.ReadMessage(); err != io.EOF {
+		t.Fatal("Expected the connection to close once the queue is closed and empty.")
+	}
+}
@@ -0,0 +1,106 @@
+// Package cirquehttp exposes queues over HTTP, for inspecting and tailing them.
+package cirquehttp
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/denis-ismailaj/cirque"
+	log "github.com/sirupsen/logrus"
+)
+
+// Most items Debug renders per queue, however many are asked for.
+const maxItems = 1000
+
+// QueueState is what Debug renders for every queue.
+type QueueState struct {
+	Name      string          `json:"name"`
+	Len       int             `json:"len"`
+	Cap       int             `json:"cap"`
+	Stats     cirque.Stats    `json:"stats"`
+	Growths   []cirque.Growth `json:"growths"`
+	OldestAge string          `json:"oldest_age,omitempty"` // Only for queues that record enqueue times
+	Items     []any           `json:"items,omitempty"`      // Only if asked for
+}
+
+// Debug is an http.Handler that renders the state of registered queues as JSON, like expvar does
+// for variables. The items query parameter includes up to that many items from the front of every
+// queue, and the name query parameter limits the output to a single queue.
+type Debug struct {
+	mu     sync.Mutex
+	queues map[string]func(items int) QueueState
+}
+
+// NewDebug creates a Debug handler with no queues.
+func NewDebug() *Debug {
+	return &Debug{queues: make(map[string]func(int) QueueState)}
+}
+
+// Register adds q to d under name, replacing any queue registered under the same name.
+// Items of q must be encodable with encoding/json to be rendered.
+func Register[T any](d *Debug, name string, q *cirque.Cirque[T]) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queues[name] = func(items int) QueueState {
+		s := QueueState{
+			Name:    name,
+			Len:     q.Len(),
+			Cap:     q.Cap(),
+			Stats:   q.Stats(),
+			Growths: q.Growths(),
+		}
+		if age, ok := q.OldestAge(); ok {
+			s.OldestAge = age.String()
+		}
+		for _, item := range q.Peek(items) {
+			s.Items = append(s.Items, item)
+		}
+		return s
+	}
+}
+
+// Unregister removes the queue registered under name.
+func (d *Debug) Unregister(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.queues, name)
+}
+
+func (d *Debug) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	items := 0
+	if v := r.URL.Query().Get("items"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid items parameter", http.StatusBadRequest)
+			return
+		}
+		items = min(n, maxItems)
+	}
+	name := r.URL.Query().Get("name")
+
+	d.mu.Lock()
+	states := make([]QueueState, 0, len(d.queues))
+	for _, n := range slices.Sorted(maps.Keys(d.queues)) {
+		if name == "" || name == n {
+			states = append(states, d.queues[n](items))
+		}
+	}
+	d.mu.Unlock()
+
+	if name != "" && len(states) == 0 {
+		http.Error(w, "unknown queue", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(states); err != nil {
+		log.Warningf("Failed to render queues: %v.", err)
+	}
+}
@@ -0,0 +1,51 @@
+package cirquehttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque"
+)
+
+func get(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestDebug(t *testing.T) {
+	d := NewDebug()
+	q := cirque.New[string](2, cirque.WithEnqueueTime[string]())
+	q.Enqueue("a", "b", "c")
+	Register(d, "jobs", q)
+	Register(d, "other", cirque.New[int](1))
+
+	rec := get(t, d, "/?name=jobs&items=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d.", rec.Code)
+	}
+	var states []QueueState
+	if err := json.NewDecoder(rec.Body).Decode(&states); err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("Expected only the named queue, got %d.", len(states))
+	}
+	s := states[0]
+	if s.Name != "jobs" || s.Len != 3 || s.Cap != q.Cap() || s.OldestAge == "" || len(s.Growths) == 0 {
+		t.Fatalf("Unexpected state %+v.", s)
+	}
+	if len(s.Items) != 2 || s.Items[0] != "a" {
+		t.Fatalf("Unexpected items %v.", s.Items)
+	}
+
+	if rec := get(t, d, "/?items=-1"); rec.Code != http.StatusBadRequest {
+		t.Fatal("Expected invalid items to be rejected.")
+	}
+	d.Unregister("jobs")
+	if rec := get(t, d, "/?name=jobs"); rec.Code != http.StatusNotFound {
+		t.Fatal("Expected the queue to be unregistered.")
+	}
+}
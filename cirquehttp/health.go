@@ -0,0 +1,35 @@
+package cirquehttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Health returns an http.Handler for readiness probes, which responds with the report returned
+// by check as JSON, with status 200 if check returns no error and 503 otherwise.
+// It fits checks like the Health method of a durable queue from the spill package.
+func Health[R any](check func() (R, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := check()
+
+		var body struct {
+			Status string `json:"status"`
+			Error  string `json:"error,omitempty"`
+			Report R      `json:"report"`
+		}
+		body.Status, body.Report = "ok", report
+		status := http.StatusOK
+		if err != nil {
+			body.Status, body.Error = "unavailable", err.Error()
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			log.Warningf("Failed to render health: %v.", err)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package cirquehttp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	var err error
+	h := Health(func() (int, error) { return 42, err })
+
+	rec := get(t, h, "/")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"report":42`) {
+		t.Fatalf("Unexpected response %d %s.", rec.Code, rec.Body.String())
+	}
+
+	err = errors.New("disk full")
+	rec = get(t, h, "/")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "disk full") {
+		t.Fatalf("Unexpected response %d %s.", rec.Code, rec.Body.String())
+	}
+}
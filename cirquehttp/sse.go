@@ -0,0 +1,96 @@
+package cirquehttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/broadcast"
+	"github.com/denis-ismailaj/cirque/codec"
+	log "github.com/sirupsen/logrus"
+)
+
+// Most items sent to a client at once.
+const sseBatch = 64
+
+// SSE is an http.Handler that streams items of a broadcast buffer to every connected client
+// as server-sent events, one event per item. Every client reads with its own reader,
+// so it gets every item written while it is connected, at its own pace.
+type SSE[T any] struct {
+	buf   *broadcast.Buffer[T]
+	codec codec.Codec[T]
+}
+
+// NewSSE creates an SSE handler that streams items of buf encoded with c.
+func NewSSE[T any](buf *broadcast.Buffer[T], c codec.Codec[T]) *SSE[T] {
+	return &SSE[T]{buf: buf, codec: c}
+}
+
+// Tail creates an SSE handler that streams items dequeued from q, keeping the last n of them for
+// clients that fall behind. Clients that fall further behind skip the items they missed, so that
+// they never hold up q. Items are dequeued until q is closed or ctx is done.
+func Tail[T any](ctx context.Context, q *cirque.Cirque[T], n int, c codec.Codec[T]) *SSE[T] {
+	buf := broadcast.New[T](n, broadcast.WithOverwrite())
+	go func() {
+		defer buf.Close()
+		for item := range q.Drain(ctx) {
+			if err := buf.Write(ctx, item); err != nil {
+				return
+			}
+		}
+	}()
+	return NewSSE(buf, c)
+}
+
+func (s *SSE[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	reader := s.buf.NewReader()
+	defer reader.Close()
+
+	// Respond right away, so that the client knows it is attached.
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		items, err := reader.Read(r.Context(), sseBatch)
+		if errors.Is(err, broadcast.ErrFellBehind) {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		var event bytes.Buffer
+		for _, item := range items {
+			data, err := s.codec.Encode(item)
+			if err != nil {
+				log.Warningf("Failed to encode item for SSE: %v.", err)
+				continue
+			}
+			writeEvent(&event, data)
+		}
+		if _, err := w.Write(event.Bytes()); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// Append data as an event, with every line of it in its own data field.
+func writeEvent(event *bytes.Buffer, data []byte) {
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		event.WriteString("data: ")
+		event.Write(line)
+		event.WriteByte('\n')
+	}
+	event.WriteByte('\n')
+}
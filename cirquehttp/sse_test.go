@@ -0,0 +1,57 @@
+package cirquehttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+func TestTail(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := cirque.New[int](4)
+	server := httptest.NewServer(Tail(ctx, q, 16, codec.JSON[int]()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatal("Expected an event stream.")
+	}
+
+	q.Enqueue(1, 2, 3)
+	q.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	expected := []string{"data: 1", "", "data: 2", "", "data: 3", ""}
+	if len(lines) != len(expected) {
+		t.Fatalf("Unexpected events %q.", lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Fatalf("Unexpected events %q.", lines)
+		}
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	var event bytes.Buffer
+	writeEvent(&event, []byte("a\nb\n"))
+	if event.String() != "data: a\ndata: b\n\n" {
+		t.Fatalf("Unexpected event %q.", event.String())
+	}
+}
@@ -0,0 +1,326 @@
+package cirquehttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrMessageTooLarge is returned when a WebSocket message exceeds maxMessage.
+var ErrMessageTooLarge = errors.New("cirquehttp: message too large")
+
+// ErrProtocol is returned when a WebSocket peer sends a frame that RFC 6455 forbids.
+var ErrProtocol = errors.New("cirquehttp: websocket protocol error")
+
+// Largest WebSocket message accepted, to keep a peer from exhausting memory.
+const maxMessage = 16 << 20
+
+// Largest payload of a control frame, and of the reason in a close frame after its status code.
+const (
+	maxControl     = 125
+	maxCloseReason = maxControl - 2
+)
+
+// Appended to the key of a WebSocket handshake before hashing it, as set by RFC 6455.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of WebSocket frames.
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Status codes of WebSocket close frames.
+const (
+	closeNormal      = 1000
+	closeProtocol    = 1002
+	closeInvalidData = 1007
+	closePolicy      = 1008
+	closeTooLarge    = 1009
+)
+
+// Conn is a minimal WebSocket connection (RFC 6455) that exchanges whole messages.
+// Extensions and subprotocols aren't supported. ReadMessage must not be called concurrently,
+// while writes can come from multiple goroutines.
+type Conn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	client bool // Whether this is the client end, which masks its frames
+	wmu    sync.Mutex
+	closed bool // Whether a close frame was sent, guarded by wmu
+}
+
+// Compute the Sec-WebSocket-Accept header for key.
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// Whether the comma-separated header contains token, ignoring case.
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade takes over the connection of a WebSocket handshake request. On failure it responds with
+// an error, so the handler only has to return.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || !headerHas(r.Header, "Connection", "upgrade") ||
+		!headerHas(r.Header, "Upgrade", "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("cirquehttp: not a websocket handshake")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket unsupported", http.StatusInternalServerError)
+		return nil, errors.New("cirquehttp: connection can't be hijacked")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, r: rw.Reader}, nil
+}
+
+// Dial opens a WebSocket connection to rawURL, with a ws, wss, http or https scheme.
+func Dial(ctx context.Context, rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	secure := u.Scheme == "wss" || u.Scheme == "https"
+	host := u.Host
+	if u.Port() == "" {
+		if secure {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if secure {
+		conn = tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+	}
+
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n",
+		u.RequestURI(), u.Host, key)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, &http.Request{Method: http.MethodGet})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("cirquehttp: websocket handshake failed with status %s", resp.Status)
+	}
+	return &Conn{conn: conn, r: r, client: true}, nil
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered along the way.
+// Once the peer closes the connection it returns io.EOF.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+		case opPong:
+		case opClose:
+			c.closeWith(closeNormal, "")
+			return nil, io.EOF
+		case opText, opBinary, opContinuation:
+			if len(message)+len(payload) > maxMessage {
+				c.closeWith(closeTooLarge, "")
+				return nil, ErrMessageTooLarge
+			}
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		}
+	}
+}
+
+// Read a single frame, unmasking its payload.
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = header[0]&0x80 != 0, header[0]&0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	// Only clients mask their frames, and control frames are never fragmented nor long.
+	if masked == c.client {
+		c.closeWith(closeProtocol, "frame masked wrongly")
+		return false, 0, nil, ErrProtocol
+	}
+	if op&0x8 != 0 && (length > maxControl || !fin) {
+		c.closeWith(closeProtocol, "invalid control frame")
+		return false, 0, nil, ErrProtocol
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxMessage {
+		c.closeWith(closeTooLarge, "")
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage sends data as a single binary message.
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opBinary, data)
+}
+
+// Send a single frame, masking its payload if this is the client end.
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closed {
+		return net.ErrClosed
+	}
+	return c.writeFrameLocked(op, payload)
+}
+
+// Must be called with wmu held.
+func (c *Conn) writeFrameLocked(op byte, payload []byte) error {
+	frame := []byte{0x80 | op}
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if !c.client {
+		frame = append(frame, payload...)
+	} else {
+		var mask [4]byte
+		rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	}
+
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// Send a close frame with the given status, unless one was sent already.
+// The reason is cut short to fit in a control frame.
+func (c *Conn) closeWith(status uint16, reason string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	// Cut at a rune boundary, as the reason must be valid UTF-8.
+	for len(reason) > maxCloseReason {
+		_, size := utf8.DecodeLastRuneInString(reason)
+		reason = reason[:len(reason)-size]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, status)
+	return c.writeFrameLocked(opClose, append(payload, reason...))
+}
+
+// Close sends a close frame and closes the underlying connection.
+func (c *Conn) Close() error {
+	c.closeWith(closeNormal, "")
+	return c.conn.Close()
+}
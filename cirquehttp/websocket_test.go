@@ -0,0 +1,141 @@
+package cirquehttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455.
+	if acceptKey("dGhlIHNhbXBsZSBub25jZQ==") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("Unexpected accept key.")
+	}
+}
+
+func TestEcho(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(msg)
+		}
+	}))
+	defer server.Close()
+
+	conn, err := Dial(context.Background(), strings.Replace(server.URL, "http", "ws", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages of every length encoding, with a ping in between.
+	for _, n := range []int{0, 125, 126, 70000} {
+		msg := bytes.Repeat([]byte{'x'}, n)
+		if err := conn.WriteMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.writeFrame(opPing, []byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		echo, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(echo, msg) {
+			t.Fatalf("Message of %d bytes changed to %d bytes.", n, len(echo))
+		}
+	}
+
+	conn.closeWith(closeNormal, "")
+	if _, err := conn.ReadMessage(); err != io.EOF {
+		t.Fatalf("Expected the server to close, got %v.", err)
+	}
+	conn.Close()
+}
+
+func TestUpgradeRejected(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if _, err := Upgrade(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err == nil || rec.Code != http.StatusBadRequest {
+		t.Fatal("Expected a plain request to be rejected.")
+	}
+}
+
+// Send a raw frame header to a server side Conn, and return the close frame it answers with.
+func rejectFrame(t *testing.T, header []byte) (uint16, error) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := &Conn{conn: server, r: bufio.NewReader(server)}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := conn.ReadMessage()
+		errs <- err
+	}()
+	if _, err := client.Write(header); err != nil {
+		t.Fatal(err)
+	}
+	var reply [2]byte
+	if _, err := io.ReadFull(client, reply[:]); err != nil {
+		t.Fatal(err)
+	}
+	if reply[0] != 0x80|opClose {
+		t.Fatalf("Expected a close frame, got opcode %#x.", reply[0])
+	}
+	payload := make([]byte, reply[1]&0x7f)
+	if _, err := io.ReadFull(client, payload); err != nil {
+		t.Fatal(err)
+	}
+	return binary.BigEndian.Uint16(payload), <-errs
+}
+
+func TestUnmaskedFrame(t *testing.T) {
+	status, err := rejectFrame(t, []byte{0x80 | opBinary, 1})
+	if err != ErrProtocol || status != closeProtocol {
+		t.Fatalf("Expected a protocol error, got %v and status %d.", err, status)
+	}
+}
+
+func TestLongControlFrame(t *testing.T) {
+	status, err := rejectFrame(t, []byte{0x80 | opPing, 0x80 | 126})
+	if err != ErrProtocol || status != closeProtocol {
+		t.Fatalf("Expected a protocol error, got %v and status %d.", err, status)
+	}
+}
+
+func TestLongCloseReason(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	conn := &Conn{conn: server, r: bufio.NewReader(server)}
+
+	go conn.closeWith(closePolicy, strings.Repeat("é", 100))
+	var header [2]byte
+	if _, err := io.ReadFull(client, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7f)
+	if length > maxControl {
+		t.Fatalf("Expected the close frame to fit in %d bytes, got %d.", maxControl, length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(client, payload); err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.Valid(payload[2:]) {
+		t.Fatal("Expected the reason to be cut at a rune boundary.")
+	}
+}
@@ -0,0 +1,172 @@
+// Package cirquetest provides conformance tests for queues that follow the contract of Cirque,
+// so that custom backends and wrappers can verify that they honor it.
+package cirquetest
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Queue is the contract of a FIFO queue like Cirque.
+type Queue[T any] interface {
+	// Enqueue adds items to the back of the queue, keeping those of a single call together.
+	Enqueue(items ...T)
+	// Dequeue returns a maximum of n items from the front of the queue, without waiting.
+	Dequeue(n int) []T
+	// Len returns the number of items in the queue.
+	Len() int
+}
+
+// Closable is a Queue that can be closed, after which waiting readers drain it and are released.
+type Closable[T any] interface {
+	Queue[T]
+	// Close closes the queue. Items already in it can still be dequeued.
+	Close()
+	// DequeueWait is like Dequeue, but waits for at least one item. Once the queue is closed
+	// and empty it returns an error.
+	DequeueWait(ctx context.Context, n int) ([]T, error)
+}
+
+// Run runs the conformance tests against queues created by newQueue, one per test.
+// Queues that implement Closable are also checked for their close semantics.
+func Run(t *testing.T, newQueue func() Queue[int]) {
+	t.Run("FIFO", func(t *testing.T) { testFIFO(t, newQueue()) })
+	t.Run("Len", func(t *testing.T) { testLen(t, newQueue()) })
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, newQueue()) })
+	if _, ok := newQueue().(Closable[int]); ok {
+		t.Run("Close", func(t *testing.T) { testClose(t, newQueue().(Closable[int])) })
+	}
+}
+
+func testFIFO(t *testing.T, q Queue[int]) {
+	if items := q.Dequeue(1); len(items) != 0 {
+		t.Fatalf("Expected nothing from an empty queue, got %v.", items)
+	}
+
+	q.Enqueue(1, 2, 3)
+	q.Enqueue()
+	q.Enqueue(4)
+	if items := q.Dequeue(0); len(items) != 0 {
+		t.Fatalf("Expected nothing when dequeueing 0 items, got %v.", items)
+	}
+	if items := q.Dequeue(2); !slices.Equal(items, []int{1, 2}) {
+		t.Fatalf("Expected [1 2], got %v.", items)
+	}
+
+	// Enough items to make a growing queue grow.
+	var expected []int
+	for i := 5; i < 100; i++ {
+		q.Enqueue(i)
+		expected = append(expected, i)
+	}
+	expected = append([]int{3, 4}, expected...)
+	if items := q.Dequeue(1000); !slices.Equal(items, expected) {
+		t.Fatalf("Items missing or reordered: %v.", items)
+	}
+}
+
+func testLen(t *testing.T, q Queue[int]) {
+	if q.Len() != 0 {
+		t.Fatalf("Expected an empty queue, got length %d.", q.Len())
+	}
+	q.Enqueue(1, 2, 3)
+	if q.Len() != 3 {
+		t.Fatalf("Expected length 3, got %d.", q.Len())
+	}
+	q.Dequeue(2)
+	if q.Len() != 1 {
+		t.Fatalf("Expected length 1, got %d.", q.Len())
+	}
+	q.Dequeue(2)
+	if q.Len() != 0 {
+		t.Fatalf("Expected length 0, got %d.", q.Len())
+	}
+}
+
+func testConcurrent(t *testing.T, q Queue[int]) {
+	const producers, batches, batch = 4, 100, 5
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for b := 0; b < batches; b++ {
+				items := make([]int, batch)
+				for i := range items {
+					items[i] = (p*batches+b)*batch + i
+				}
+				q.Enqueue(items...)
+			}
+		}(p)
+	}
+
+	// Items of every producer arrive in order, and batches are never split up by other producers.
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	next := make([]int, producers)
+	received, prev := 0, -1
+	for received < producers*batches*batch {
+		items := q.Dequeue(7)
+		if len(items) == 0 {
+			select {
+			case <-done:
+				if q.Len() == 0 {
+					t.Fatalf("Expected %d items, got %d.", producers*batches*batch, received)
+				}
+			default:
+				runtime.Gosched()
+			}
+			continue
+		}
+		for _, item := range items {
+			p := item / (batches * batch)
+			if item != p*batches*batch+next[p] {
+				t.Fatalf("Items of producer %d missing or reordered.", p)
+			}
+			next[p]++
+			if item%batch != 0 && item != prev+1 {
+				t.Fatalf("Batch of item %d split up by another producer.", item)
+			}
+			prev = item
+		}
+		received += len(items)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("Expected an empty queue, got length %d.", q.Len())
+	}
+}
+
+func testClose(t *testing.T, q Closable[int]) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A reader gets items enqueued after it started, whether or not it was already waiting by then.
+	released := make(chan error)
+	go func() {
+		_, err := q.DequeueWait(ctx, 1)
+		released <- err
+	}()
+	q.Enqueue(1, 2)
+	if err := <-released; err != nil {
+		t.Fatalf("Expected the reader to get an item, got %v.", err)
+	}
+
+	q.Close()
+	q.Close()
+	if items, err := q.DequeueWait(ctx, 10); err != nil || !slices.Equal(items, []int{2}) {
+		t.Fatalf("Expected the remaining items after close, got %v and %v.", items, err)
+	}
+	_, err := q.DequeueWait(ctx, 1)
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a closed and empty queue to release readers with an error, got %v.", err)
+	}
+}
@@ -0,0 +1,20 @@
+package cirquetest
+
+import (
+	"testing"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/ordered"
+)
+
+func TestCirque(t *testing.T) {
+	Run(t, func() Queue[int] { return cirque.New[int](4) })
+}
+
+func TestBoundedCirque(t *testing.T) {
+	Run(t, func() Queue[int] { return cirque.New(4, cirque.WithBound[int](1000)) })
+}
+
+func TestOrderedQueue(t *testing.T) {
+	Run(t, func() Queue[int] { return ordered.NewQueue[int](4) })
+}
@@ -0,0 +1,41 @@
+// Package clock abstracts the passage of time, so time-driven behavior can be tested with a fake clock.
+package clock
+
+import "time"
+
+// Clock tells the current time and schedules functions to run later.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a function scheduled with AfterFunc.
+type Timer interface {
+	// Stop prevents the function from running, and reports whether it did.
+	Stop() bool
+}
+
+// Real is the Clock backed by the time package.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// After returns a channel that is closed once d has passed on c, along with the timer
+// that closes it, which should be stopped once the channel is no longer needed.
+func After(c Clock, d time.Duration) (<-chan struct{}, Timer) {
+	ch := make(chan struct{})
+	return ch, c.AfterFunc(d, func() { close(ch) })
+}
+
+// Since returns the time elapsed on c since t.
+func Since(c Clock, t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
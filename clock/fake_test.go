@@ -0,0 +1,58 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAdvance(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFake(start)
+
+	var fired []int
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	c.AfterFunc(time.Second, func() {
+		fired = append(fired, 1)
+		if !c.Now().Equal(start.Add(time.Second)) {
+			t.Error("Expected the clock to be set at the due time.")
+		}
+	})
+	stopped := c.AfterFunc(time.Second, func() { fired = append(fired, 0) })
+
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatal("Expected Stop to succeed only once.")
+	}
+
+	c.Advance(1500 * time.Millisecond)
+	if len(fired) != 1 || fired[0] != 1 {
+		t.Fatalf("Unexpected timers fired %v.", fired)
+	}
+
+	c.Advance(time.Second)
+	if len(fired) != 2 || fired[1] != 2 {
+		t.Fatalf("Unexpected timers fired %v.", fired)
+	}
+	if !c.Now().Equal(start.Add(2500 * time.Millisecond)) {
+		t.Fatal("Clock not advanced.")
+	}
+}
+
+func TestAfter(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	due, _ := After(c, time.Second)
+	_, stopped := After(c, time.Second)
+	stopped.Stop()
+
+	if c.Pending() != 1 {
+		t.Fatalf("Expected 1 pending timer, got %d.", c.Pending())
+	}
+	c.Advance(time.Second)
+	select {
+	case <-due:
+	default:
+		t.Fatal("Expected the channel to be closed once due.")
+	}
+	if Since(c, time.Unix(0, 0)) != time.Second {
+		t.Fatal("Expected a second to have passed.")
+	}
+}
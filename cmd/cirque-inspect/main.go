@@ -0,0 +1,195 @@
+// Command cirque-inspect inspects the directory of a durable queue from the spill package.
+//
+// Usage:
+//
+//	cirque-inspect list DIR                 list segments with their record counts
+//	cirque-inspect cat [-format F] DIR [SEG] print records, of every segment unless SEG is given
+//	cirque-inspect verify DIR               check every record, exiting with 1 on damage
+//	cirque-inspect truncate DIR             cut damaged tails off segments
+//
+// Records are printed as text, hex or indented JSON depending on -format, since they are
+// stored as encoded by the codec of the queue. Encrypted queues can't be printed.
+// The queue must not be in use while its directory is truncated.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/denis-ismailaj/cirque/spill"
+)
+
+// errDamaged makes verify exit with a failure.
+var errDamaged = errors.New("damaged segments found")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "cirque-inspect:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("expected a command: list, cat, verify or truncate")
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	format := fs.String("format", "text", "how to print records: text, hex or json")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("expected a queue directory")
+	}
+	if _, err := os.Stat(fs.Arg(0)); err != nil {
+		return err
+	}
+	store, err := spill.Dir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		return list(store, out)
+	case "cat":
+		return cat(store, fs.Arg(1), *format, out)
+	case "verify":
+		return verify(store, out)
+	case "truncate":
+		return truncate(store, out)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func list(store spill.Store, out io.Writer) error {
+	segments, err := spill.Segments(store)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, s := range segments {
+		fmt.Fprintf(out, "%s\tv%d\t%d records\t%d bytes", s.Name, s.Version, s.Records, s.Size)
+		if s.Err != nil {
+			fmt.Fprintf(out, "\t%v", s.Err)
+		}
+		fmt.Fprintln(out)
+		total += s.Records
+	}
+	fmt.Fprintf(out, "%d segments, %d records\n", len(segments), total)
+
+	if offset, ok, err := spill.Consumed(store); err != nil {
+		return err
+	} else if ok {
+		fmt.Fprintf(out, "consumed up to offset %d\n", offset)
+	}
+	return nil
+}
+
+func cat(store spill.Store, name, format string, out io.Writer) error {
+	names := []string{name}
+	if name == "" {
+		segments, err := spill.Segments(store)
+		if err != nil {
+			return err
+		}
+		names = names[:0]
+		for _, s := range segments {
+			names = append(names, s.Name)
+		}
+	}
+
+	for _, name := range names {
+		records, err := spill.Records(store, name)
+		if err != nil && err != spill.ErrCorrupt {
+			return err
+		}
+		for _, r := range records {
+			if err := printRecord(r, format, out); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(out, "# %s: %v\n", name, err)
+		}
+	}
+	return nil
+}
+
+func printRecord(r []byte, format string, out io.Writer) error {
+	switch format {
+	case "text":
+		_, err := fmt.Fprintf(out, "%s\n", r)
+		return err
+	case "hex":
+		_, err := fmt.Fprintln(out, hex.EncodeToString(r))
+		return err
+	case "json":
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, r, "", "  "); err != nil {
+			return fmt.Errorf("record isn't JSON: %w", err)
+		}
+		_, err := fmt.Fprintln(out, buf.String())
+		return err
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func verify(store spill.Store, out io.Writer) error {
+	segments, err := spill.Segments(store)
+	if err != nil {
+		return err
+	}
+	damaged := 0
+	for _, s := range segments {
+		if s.Err != nil {
+			fmt.Fprintf(out, "%s: %v after %d records\n", s.Name, s.Err, s.Records)
+			damaged++
+		}
+	}
+	if damaged > 0 {
+		return errDamaged
+	}
+	fmt.Fprintf(out, "%d segments OK\n", len(segments))
+	return nil
+}
+
+func truncate(store spill.Store, out io.Writer) error {
+	// Finish repairs that an earlier run didn't get to complete first.
+	interrupted, err := spill.Interrupted(store)
+	if err != nil {
+		return err
+	}
+	for _, name := range interrupted {
+		kept, err := spill.Repair(store, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: kept %d records\n", name, kept)
+	}
+
+	segments, err := spill.Segments(store)
+	if err != nil {
+		return err
+	}
+	for _, s := range segments {
+		if s.Err != spill.ErrCorrupt {
+			continue
+		}
+		kept, err := spill.Repair(store, s.Name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: kept %d records\n", s.Name, kept)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+	"github.com/denis-ismailaj/cirque/spill"
+)
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	store, err := spill.Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := spill.OpenDurable(store, codec.JSON[string]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	var out bytes.Buffer
+	if err := run([]string{"cat", dir}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "\"a\"\n\"b\"\n" {
+		t.Fatalf("Unexpected records %q.", out.String())
+	}
+
+	// Cut the last record short.
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	data, _ := os.ReadFile(segments[0])
+	os.WriteFile(segments[0], data[:len(data)-1], 0o600)
+
+	out.Reset()
+	if err := run([]string{"verify", dir}, &out); err != errDamaged {
+		t.Fatalf("Expected damage to be found, got %v.", err)
+	}
+	if err := run([]string{"truncate", dir}, &out); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := run([]string{"list", dir}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "1 segments, 1 records") {
+		t.Fatalf("Unexpected listing %q.", out.String())
+	}
+}
@@ -0,0 +1,51 @@
+// Package codec provides ways of turning items into bytes and back, for queues that store or send them.
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec encodes items of type T into bytes and decodes them back.
+type Codec[T any] interface {
+	Encode(item T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSON returns a Codec that uses encoding/json.
+func JSON[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Encode(item T) ([]byte, error) {
+	return json.Marshal(item)
+}
+
+func (jsonCodec[T]) Decode(data []byte) (T, error) {
+	var item T
+	err := json.Unmarshal(data, &item)
+	return item, err
+}
+
+// Gob returns a Codec that uses encoding/gob. Every item is encoded on its own, so type
+// information is repeated in every encoding.
+func Gob[T any]() Codec[T] {
+	return gobCodec[T]{}
+}
+
+type gobCodec[T any] struct{}
+
+func (gobCodec[T]) Encode(item T) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(item)
+	return buf.Bytes(), err
+}
+
+func (gobCodec[T]) Decode(data []byte) (T, error) {
+	var item T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&item)
+	return item, err
+}
@@ -0,0 +1,24 @@
+package codec
+
+import "testing"
+
+type event struct {
+	Name  string
+	Count int
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, c := range map[string]Codec[event]{"json": JSON[event](), "gob": Gob[event]()} {
+		data, err := c.Encode(event{"a", 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := c.Decode(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e != (event{"a", 1}) {
+			t.Fatalf("Item changed by %s codec: %+v.", name, e)
+		}
+	}
+}
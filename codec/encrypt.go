@@ -0,0 +1,48 @@
+package codec
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+)
+
+// ErrDecrypt is returned when encrypted data can't be decrypted, like when it was tampered
+// with or encrypted with another key.
+var ErrDecrypt = errors.New("codec: can't decrypt")
+
+// Encrypted returns a Codec that encrypts the encodings of c with aead, so that items never
+// touch storage in plaintext. Every encoding gets a random nonce, which is stored along with it.
+// The key of aead is up to the caller, like an AES key with cipher.NewGCM.
+func Encrypted[T any](c Codec[T], aead cipher.AEAD) Codec[T] {
+	return encryptedCodec[T]{c: c, aead: aead}
+}
+
+type encryptedCodec[T any] struct {
+	c    Codec[T]
+	aead cipher.AEAD
+}
+
+func (e encryptedCodec[T]) Encode(item T) ([]byte, error) {
+	plain, err := e.c.Encode(item)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(plain)+e.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (e encryptedCodec[T]) Decode(data []byte) (T, error) {
+	var item T
+	if len(data) < e.aead.NonceSize() {
+		return item, ErrDecrypt
+	}
+	nonce, sealed := data[:e.aead.NonceSize()], data[e.aead.NonceSize():]
+	plain, err := e.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return item, ErrDecrypt
+	}
+	return e.c.Decode(plain)
+}
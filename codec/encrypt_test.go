@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func newAEAD(t *testing.T, key byte) cipher.AEAD {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{key}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func TestEncrypted(t *testing.T) {
+	c := Encrypted(JSON[event](), newAEAD(t, 1))
+
+	data, err := c.Encode(event{"secret", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("Expected the encoding not to contain plaintext.")
+	}
+	e, err := c.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e != (event{"secret", 1}) {
+		t.Fatalf("Item changed by encryption: %+v.", e)
+	}
+
+	if _, err := Encrypted(JSON[event](), newAEAD(t, 2)).Decode(data); err != ErrDecrypt {
+		t.Fatal("Expected decryption with another key to fail.")
+	}
+	data[len(data)-1] ^= 1
+	if _, err := c.Decode(data); err != ErrDecrypt {
+		t.Fatal("Expected tampered data to fail decryption.")
+	}
+}
@@ -0,0 +1,204 @@
+// Package connect defines the contract between queues and external systems, like message brokers,
+// and pumps that move items between them with batching and backoff.
+package connect
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/retry"
+	log "github.com/sirupsen/logrus"
+)
+
+// Source is implemented by adapters that receive items from an external system.
+type Source[T any] interface {
+	// Receive returns a maximum of n items, waiting until there is at least one.
+	// It returns io.EOF once the source is exhausted, possibly along with its last items.
+	Receive(ctx context.Context, n int) ([]T, error)
+}
+
+// Sink is implemented by adapters that send items to an external system.
+type Sink[T any] interface {
+	// Send delivers items, either all of them or, on error, none that need to be sent again.
+	Send(ctx context.Context, items []T) error
+}
+
+// Option configures a pump.
+type Option[T any] func(*config[T])
+
+type config[T any] struct {
+	batch  int
+	linger time.Duration
+	policy retry.Policy
+	giveUp func(items []T, err error)
+	clock  clock.Clock
+}
+
+// WithBatch sets the most items moved at once. The default is 100.
+func WithBatch[T any](n int) Option[T] {
+	return func(c *config[T]) {
+		if n > 0 {
+			c.batch = n
+		}
+	}
+}
+
+// WithLinger makes Drain wait up to d for a batch to fill up before sending it, trading latency
+// for fewer and larger sends. By default it sends whatever is in the queue right away.
+func WithLinger[T any](d time.Duration) Option[T] {
+	return func(c *config[T]) {
+		c.linger = d
+	}
+}
+
+// WithBackoff sets how failed receives and sends are retried. By default they are retried forever,
+// after 100ms doubling up to 30s.
+func WithBackoff[T any](p retry.Policy) Option[T] {
+	return func(c *config[T]) {
+		c.policy = p
+	}
+}
+
+// OnGiveUp makes Drain call f with every batch that ran out of attempts, along with the error of
+// the last attempt. By default those batches are dropped.
+func OnGiveUp[T any](f func(items []T, err error)) Option[T] {
+	return func(c *config[T]) {
+		c.giveUp = f
+	}
+}
+
+// WithClock makes the pumps time backoff and linger with c instead of the real clock.
+func WithClock[T any](clk clock.Clock) Option[T] {
+	return func(c *config[T]) {
+		c.clock = clk
+	}
+}
+
+func newConfig[T any](opts []Option[T]) config[T] {
+	c := config[T]{
+		batch:  100,
+		policy: retry.Policy{Initial: 100 * time.Millisecond, Max: 30 * time.Second},
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Wait for d on clk, or return the context's error if ctx is done first.
+func sleep(ctx context.Context, clk clock.Clock, d time.Duration) error {
+	due, timer := clock.After(clk, d)
+	defer timer.Stop()
+	select {
+	case <-due:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Feed moves items from src into q until src is exhausted, in which case it returns nil, or ctx is done.
+// Items are enqueued with EnqueueWait, so a full bounded queue holds back receiving. Failed receives
+// are retried with backoff, and once they run out of attempts Feed returns the last error.
+func Feed[T any](ctx context.Context, src Source[T], q *cirque.Cirque[T], opts ...Option[T]) error {
+	c := newConfig(opts)
+
+	attempt := 0
+	for {
+		items, err := src.Receive(ctx, c.batch)
+		if len(items) > 0 {
+			if err := q.EnqueueWait(ctx, items...); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			attempt = 0
+			continue
+		}
+
+		attempt++
+		if c.policy.MaxAttempts > 0 && attempt >= c.policy.MaxAttempts {
+			return err
+		}
+		log.Warningf("Failed to receive items, attempt %d: %v.", attempt, err)
+		if err := sleep(ctx, c.clock, c.policy.Backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// Drain moves items from q to sink in batches until q is closed and empty, in which case it returns nil,
+// or ctx is done. Failed sends are retried with backoff, and batches that run out of attempts are given up on.
+func Drain[T any](ctx context.Context, q *cirque.Cirque[T], sink Sink[T], opts ...Option[T]) error {
+	c := newConfig(opts)
+
+	for {
+		items, err := q.DequeueWait(ctx, c.batch)
+		if errors.Is(err, cirque.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c.linger > 0 && len(items) < c.batch {
+			items = fill(ctx, q, items, c.batch, c.linger, c.clock)
+		}
+
+		if err := send(ctx, sink, items, c); err != nil {
+			return err
+		}
+	}
+}
+
+// Add items from q to batch until it holds n items or linger passes on clk.
+func fill[T any](ctx context.Context, q *cirque.Cirque[T], batch []T, n int, linger time.Duration, clk clock.Clock) []T {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := clk.AfterFunc(linger, cancel)
+	defer timer.Stop()
+
+	for len(batch) < n {
+		items, err := q.DequeueWait(ctx, n-len(batch))
+		if err != nil {
+			break
+		}
+		batch = append(batch, items...)
+	}
+	return batch
+}
+
+// Send items to sink, retrying according to the policy of c. It only fails if ctx is done.
+func send[T any](ctx context.Context, sink Sink[T], items []T, c config[T]) error {
+	for attempt := 1; ; attempt++ {
+		err := sink.Send(ctx, items)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if c.policy.MaxAttempts > 0 && attempt >= c.policy.MaxAttempts {
+			log.Warningf("Giving up on %d items after %d attempts: %v.", len(items), attempt, err)
+			if c.giveUp != nil {
+				c.giveUp(items, err)
+			}
+			return nil
+		}
+		log.Debugf("Failed to send %d items, attempt %d: %v.", len(items), attempt, err)
+		if err := sleep(ctx, c.clock, c.policy.Backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
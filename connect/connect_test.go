@@ -0,0 +1,159 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/retry"
+)
+
+// sliceSource hands out its items, failing every other receive.
+type sliceSource struct {
+	items []int
+	calls int
+}
+
+func (s *sliceSource) Receive(_ context.Context, n int) ([]int, error) {
+	s.calls++
+	if s.calls%2 == 0 {
+		return nil, errors.New("flaky")
+	}
+	n = min(n, len(s.items))
+	items := s.items[:n]
+	s.items = s.items[n:]
+	if len(s.items) == 0 {
+		return items, io.EOF
+	}
+	return items, nil
+}
+
+// recordingSink keeps the batches it gets, after failing as many sends as failures.
+type recordingSink struct {
+	mu       sync.Mutex
+	batches  [][]int
+	failures int
+}
+
+func (s *recordingSink) Send(_ context.Context, items []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	s.batches = append(s.batches, slices.Clone(items))
+	return nil
+}
+
+var fast = retry.Policy{Initial: time.Millisecond}
+
+func TestFeed(t *testing.T) {
+	src := &sliceSource{items: []int{1, 2, 3, 4, 5}}
+	q := cirque.New[int](4)
+
+	if err := Feed(context.Background(), src, q, WithBatch[int](2), WithBackoff[int](fast)); err != nil {
+		t.Fatal(err)
+	}
+	if items := q.Dequeue(10); !slices.Equal(items, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	q := cirque.New[int](4)
+	q.Enqueue(1, 2, 3, 4, 5)
+	q.Close()
+
+	sink := &recordingSink{failures: 2}
+	if err := Drain(context.Background(), q, sink, WithBatch[int](2), WithBackoff[int](fast)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.batches) != 3 || !slices.Equal(slices.Concat(sink.batches...), []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("Unexpected batches %v.", sink.batches)
+	}
+}
+
+func TestDrainGiveUp(t *testing.T) {
+	q := cirque.New[int](4)
+	q.Enqueue(1, 2)
+	q.Close()
+
+	var given []int
+	sink := &recordingSink{failures: 2}
+	policy := retry.Policy{Initial: time.Millisecond, MaxAttempts: 2}
+	err := Drain(context.Background(), q, sink, WithBackoff[int](policy), OnGiveUp(func(items []int, _ error) {
+		given = append(given, items...)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(given, []int{1, 2}) || len(sink.batches) != 0 {
+		t.Fatalf("Expected the batch to be given up on, got %v.", given)
+	}
+}
+
+func TestDrainLinger(t *testing.T) {
+	q := cirque.New[int](4)
+	sink := &recordingSink{}
+
+	done := make(chan error)
+	go func() {
+		done <- Drain(context.Background(), q, sink, WithBatch[int](3), WithLinger[int](time.Second))
+	}()
+	q.Enqueue(1)
+	time.Sleep(10 * time.Millisecond)
+	q.Enqueue(2, 3)
+	q.Close()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.batches) != 1 || len(sink.batches[0]) != 3 {
+		t.Fatalf("Expected a single full batch, got %v.", sink.batches)
+	}
+}
+
+func TestDrainClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	q := cirque.New[int](4)
+	q.Enqueue(1)
+	sink := &recordingSink{failures: 1}
+	waitForTimer := func() {
+		for c.Pending() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- Drain(context.Background(), q, sink, WithBatch[int](3), WithLinger[int](time.Minute),
+			WithBackoff[int](retry.Policy{Initial: time.Hour}), WithClock[int](c))
+	}()
+
+	// The batch lingers until the clock says so, then its failed send backs off for an hour.
+	waitForTimer()
+	c.Advance(time.Minute)
+	waitForTimer()
+	sink.mu.Lock()
+	sent := len(sink.batches)
+	sink.mu.Unlock()
+	if sent != 0 {
+		t.Fatal("Expected the send to back off.")
+	}
+	c.Advance(time.Hour)
+
+	q.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.batches) != 1 || !slices.Equal(sink.batches[0], []int{1}) {
+		t.Fatalf("Unexpected batches %v.", sink.batches)
+	}
+}
@@ -0,0 +1,111 @@
+package cirque
+
+import (
+	"time"
+
+	"github.com/denis-ismailaj/cirque/deque"
+	log "github.com/sirupsen/logrus"
+)
+
+// WithDedup suppresses enqueueing items whose key, as returned by key, matches that of an item
+// enqueued among the last n items or within the last d. Either bound can be disabled by setting it
+// to zero, but not both. For comparable item types key can simply return the item itself.
+// Suppressed items are counted in Stats as rejected.
+func WithDedup[T any, K comparable](key func(T) K, n int, d time.Duration) Option[T] {
+	return func(cq *Cirque[T]) {
+		if n <= 0 && d <= 0 {
+			return
+		}
+		w := &dedupWindow[K]{
+			n:     n,
+			d:     d,
+			seen:  make(map[K]int),
+			order: deque.New[dedupEntry[K]](max(n, 1)),
+		}
+		cq.admit = func(item T) bool {
+			return w.admit(key(item), cq.clock.Now())
+		}
+	}
+}
+
+// EnqueueUnique adds item to the queue unless an item equal to it according to eq is already
+// in the queue, and reports whether it was added. Unlike WithDedup, items don't need a comparable
+// key, at the cost of a scan over the queue, so it suits short queues. Skipped items are counted
+// in Stats as rejected. On a bounded queue, an item that doesn't fit right now is rejected too,
+// as with TryEnqueue.
+func (cq *Cirque[T]) EnqueueUnique(item T, eq func(a, b T) bool) bool {
+	// Holding the write lock keeps other writers from adding an equal item after the scan.
+	cq.writeMu.Lock()
+	defer cq.writeMu.Unlock()
+
+	for _, pending := range cq.peek(-1) {
+		if eq(pending, item) {
+			log.Debugf("Skipped item already in the queue.")
+			cq.stats.rejected.Add(1)
+			return false
+		}
+	}
+
+	var cost int64
+	if cq.bound.limit > 0 {
+		cost = cq.bound.cost(item)
+		if ok, _ := cq.bound.acquire(cost, cq.bound.limit); !ok {
+			cq.reject([]T{item}, ErrFull)
+			return false
+		}
+	}
+	admitted, err := cq.enqueueLocked([]T{item}, nil, time.Time{})
+	if len(admitted) == 0 && cq.bound.limit > 0 {
+		cq.bound.release(cost)
+	}
+	if err != nil {
+		cq.reject([]T{item}, err)
+	}
+	return len(admitted) == 1
+}
+
+// dedupWindow remembers the keys of recently admitted items, oldest first.
+type dedupWindow[K comparable] struct {
+	n     int
+	d     time.Duration
+	seen  map[K]int // Number of occurrences of every key in the window
+	order *deque.Deque[dedupEntry[K]]
+	len   int
+}
+
+type dedupEntry[K comparable] struct {
+	key K
+	at  time.Time
+}
+
+// Report whether k is not in the window, and add it if so.
+func (w *dedupWindow[K]) admit(k K, now time.Time) bool {
+	// Forget keys that have aged out of the window.
+	if w.d > 0 {
+		for e, ok := w.order.Front(); ok && now.Sub(e.at) >= w.d; e, ok = w.order.Front() {
+			w.evict()
+		}
+	}
+
+	if w.seen[k] > 0 {
+		return false
+	}
+
+	w.order.PushBack(dedupEntry[K]{key: k, at: now})
+	w.seen[k]++
+	w.len++
+
+	// Forget the oldest key if the window is over its size.
+	if w.n > 0 && w.len > w.n {
+		w.evict()
+	}
+	return true
+}
+
+func (w *dedupWindow[K]) evict() {
+	e, _ := w.order.PopFront()
+	w.len--
+	if w.seen[e.key]--; w.seen[e.key] == 0 {
+		delete(w.seen, e.key)
+	}
+}
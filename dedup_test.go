@@ -0,0 +1,65 @@
+package cirque
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDedupCount(t *testing.T) {
+	cq := New[string](4, WithDedup(func(s string) string { return s }, 2, 0))
+
+	cq.Enqueue("a", "b", "a", "c", "b", "a")
+
+	// "a" is a duplicate until two other items have been enqueued after it.
+	if items := cq.Dequeue(10); !slices.Equal(items, []string{"a", "b", "c", "a"}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if cq.Stats().Rejected != 2 {
+		t.Fatalf("Expected 2 rejected items, got %d.", cq.Stats().Rejected)
+	}
+}
+
+func TestDedupDuration(t *testing.T) {
+	type delivery struct {
+		id      string
+		payload []byte
+	}
+	cq := New[delivery](4, WithDedup(func(d delivery) string { return d.id }, 0, 10*time.Millisecond))
+
+	cq.Enqueue(delivery{id: "1"}, delivery{id: "1"})
+	if cq.Len() != 1 {
+		t.Fatal("Expected duplicate delivery to be suppressed.")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	cq.Enqueue(delivery{id: "1"})
+	if cq.Len() != 2 {
+		t.Fatal("Expected delivery to be accepted after the window passed.")
+	}
+}
+
+func TestEnqueueUnique(t *testing.T) {
+	type job struct {
+		name string
+		args []string
+	}
+	eq := func(a, b job) bool { return a.name == b.name && slices.Equal(a.args, b.args) }
+	cq := New[job](4)
+
+	if !cq.EnqueueUnique(job{"build", []string{"-v"}}, eq) || !cq.EnqueueUnique(job{"build", nil}, eq) {
+		t.Fatal("Expected distinct items to be enqueued.")
+	}
+	if cq.EnqueueUnique(job{"build", []string{"-v"}}, eq) {
+		t.Fatal("Expected an item already in the queue to be skipped.")
+	}
+
+	// Once dequeued, an equal item can be enqueued again.
+	cq.Dequeue(1)
+	if !cq.EnqueueUnique(job{"build", []string{"-v"}}, eq) {
+		t.Fatal("Expected an item no longer in the queue to be enqueued.")
+	}
+	if cq.Len() != 2 || cq.Stats().Rejected != 1 {
+		t.Fatalf("Expected 2 items and 1 rejected, got %d and %d.", cq.Len(), cq.Stats().Rejected)
+	}
+}
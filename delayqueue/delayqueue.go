@@ -0,0 +1,178 @@
+// Package delayqueue provides a queue where every item becomes available only once its ready time arrives.
+package delayqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// ErrClosed is returned by Wait once the queue is closed and empty.
+var ErrClosed = errors.New("delayqueue: queue closed")
+
+// Queue holds items until their ready time. Items are dequeued in order of ready time,
+// and items with the same ready time in the order they were enqueued.
+type Queue[T any] struct {
+	mu      sync.Mutex
+	items   entries[T]
+	seq     uint64        // Insertion counter used to break ties
+	changed chan struct{} // Closed and replaced whenever an item is enqueued or the queue is closed
+	closed  bool
+	clock   clock.Clock
+}
+
+// Option configures a Queue.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the queue tell when items are ready with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// New creates an empty Queue.
+func New[T any](opts ...Option) *Queue[T] {
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Queue[T]{changed: make(chan struct{}), clock: cfg.clock}
+}
+
+// Len returns the number of items in the queue, whether they are ready or not.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Enqueue adds item to the queue, to become available at readyAt.
+func (q *Queue[T]) Enqueue(item T, readyAt time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	heap.Push(&q.items, entry[T]{item: item, readyAt: readyAt, seq: q.seq})
+	q.seq++
+
+	// The new item may be due earlier than the one waiters are sleeping on.
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// EnqueueAfter adds item to the queue, to become available after d.
+func (q *Queue[T]) EnqueueAfter(item T, d time.Duration) {
+	q.Enqueue(item, q.clock.Now().Add(d))
+}
+
+// Dequeue returns a maximum of n items whose ready time has arrived, without waiting.
+func (q *Queue[T]) Dequeue(n int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.dequeue(n, q.clock.Now())
+}
+
+// Must be called with mu held.
+func (q *Queue[T]) dequeue(n int, now time.Time) []T {
+	var result []T
+	for len(result) < n && len(q.items) > 0 && !q.items[0].readyAt.After(now) {
+		result = append(result, heap.Pop(&q.items).(entry[T]).item)
+	}
+	return result
+}
+
+// Wait returns a maximum of n items whose ready time has arrived, waiting until at least
+// one of them is due. It returns ErrClosed once the queue is closed and empty, or the
+// context's error if ctx is done first.
+func (q *Queue[T]) Wait(ctx context.Context, n int) ([]T, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	for {
+		q.mu.Lock()
+		now := q.clock.Now()
+		if result := q.dequeue(n, now); len(result) > 0 {
+			q.mu.Unlock()
+			return result, nil
+		}
+		if q.closed && len(q.items) == 0 {
+			q.mu.Unlock()
+			return nil, ErrClosed
+		}
+
+		// Sleep until the earliest item is due, or something changes.
+		var timer clock.Timer
+		var due <-chan struct{}
+		if len(q.items) > 0 {
+			due, timer = clock.After(q.clock, q.items[0].readyAt.Sub(now))
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-due:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close marks the queue as closed. Items already in the queue still become available at
+// their ready time, after which Wait returns ErrClosed. Close is idempotent.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+type entry[T any] struct {
+	item    T
+	readyAt time.Time
+	seq     uint64
+}
+
+// entries is a min-heap of entries ordered by ready time and then insertion order.
+type entries[T any] []entry[T]
+
+func (e entries[T]) Len() int { return len(e) }
+
+func (e entries[T]) Less(i, j int) bool {
+	if e[i].readyAt.Equal(e[j].readyAt) {
+		return e[i].seq < e[j].seq
+	}
+	return e[i].readyAt.Before(e[j].readyAt)
+}
+
+func (e entries[T]) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+
+func (e *entries[T]) Push(x any) { *e = append(*e, x.(entry[T])) }
+
+func (e *entries[T]) Pop() any {
+	old := *e
+	x := old[len(old)-1]
+	old[len(old)-1] = entry[T]{}
+	*e = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,80 @@
+package delayqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestDelayQueue(t *testing.T) {
+	q := New[string]()
+	now := time.Now()
+
+	q.Enqueue("later", now.Add(30*time.Millisecond))
+	q.Enqueue("first", now.Add(-time.Second))
+	q.Enqueue("second", now.Add(-time.Second))
+	q.Enqueue("soon", now.Add(10*time.Millisecond))
+
+	items := q.Dequeue(10)
+	if len(items) != 2 || items[0] != "first" || items[1] != "second" {
+		t.Fatalf("Expected only the due items in order, got %v.", items)
+	}
+
+	for _, expected := range []string{"soon", "later"} {
+		items, err := q.Wait(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0] != expected {
+			t.Fatalf("Expected %q, got %v.", expected, items)
+		}
+		if time.Now().Before(now.Add(10 * time.Millisecond)) {
+			t.Fatal("Item returned before its ready time.")
+		}
+	}
+
+	q.Close()
+	if _, err := q.Wait(context.Background(), 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Expected ErrClosed, got %v.", err)
+	}
+}
+
+func TestWaitWakesOnEarlierItem(t *testing.T) {
+	q := New[int]()
+	q.EnqueueAfter(1, time.Hour)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.EnqueueAfter(2, 0)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	items, err := q.Wait(ctx, 1)
+	if err != nil || items[0] != 2 {
+		t.Fatalf("Expected the earlier item, got %v, %v.", items, err)
+	}
+}
+
+func TestWaitClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	q := New[string](WithClock(c))
+	q.EnqueueAfter("a", time.Hour)
+
+	result := make(chan []string)
+	go func() {
+		items, _ := q.Wait(context.Background(), 1)
+		result <- items
+	}()
+
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(time.Hour)
+	if items := <-result; len(items) != 1 || items[0] != "a" {
+		t.Fatalf("Expected the item once the fake clock got to it, got %v.", items)
+	}
+}
@@ -0,0 +1,163 @@
+// Package deque provides a double-ended queue backed by a circular list (Ring from container/ring)
+// that grows in place like Cirque.
+package deque
+
+import (
+	"container/ring"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Deque is a double-ended queue backed by a circular list.
+// Items occupy the positions from front up to (but not including) back.
+type Deque[T any] struct {
+	mu    sync.Mutex
+	front *ring.Ring // Position of the first item
+	back  *ring.Ring // Position after the last item
+	len   int        // Number of items in deque
+	cap   int        // Capacity of deque
+}
+
+// New creates a Deque of initial size n with items of type T.
+func New[T any](n int) *Deque[T] {
+	if n <= 0 {
+		return nil
+	}
+	d := new(Deque[T])
+	d.cap = n
+	d.front = ring.New(n)
+	d.back = d.front
+	return d
+}
+
+// Len returns the number of items currently in the deque.
+func (d *Deque[T]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.len
+}
+
+// Raise the capacity of the deque so that at least min more items fit.
+// Must be called with mu held.
+func (d *Deque[T]) reserve(min int) {
+	if d.cap-d.len >= min {
+		return
+	}
+
+	// New positions go between the last item and the first one,
+	// so that both ends can use them.
+	n := d.cap + min
+	last := d.back.Prev()
+	last.Link(ring.New(n))
+	d.back = last.Next()
+	if d.len == 0 {
+		d.front = d.back
+	}
+	d.cap += n
+
+	log.Debugf("Grew deque capacity to %d.", d.cap)
+}
+
+// PushBack adds items to the back of the deque, in order.
+func (d *Deque[T]) PushBack(items ...T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.reserve(len(items))
+	for _, item := range items {
+		d.back.Value = item
+		d.back = d.back.Next()
+	}
+	d.len += len(items)
+}
+
+// PushFront adds items to the front of the deque, keeping their order,
+// so that the first of them is the first to be popped from the front.
+func (d *Deque[T]) PushFront(items ...T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.reserve(len(items))
+	for i := len(items) - 1; i >= 0; i-- {
+		d.front = d.front.Prev()
+		d.front.Value = items[i]
+	}
+	d.len += len(items)
+}
+
+// Return the item stored in a ring value.
+func unbox[T any](v any) T {
+	// Nil items of interface types are stored as nil, which only converts back without checking.
+	item, _ := v.(T)
+	return item
+}
+
+// PopFront removes and returns the first item, or reports false if the deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var item T
+	if d.len == 0 {
+		return item, false
+	}
+	item = unbox[T](d.front.Value)
+	// Drop the reference so that the item can be garbage collected.
+	d.front.Value = nil
+	d.front = d.front.Next()
+	d.len--
+	return item, true
+}
+
+// PopBack removes and returns the last item, or reports false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var item T
+	if d.len == 0 {
+		return item, false
+	}
+	d.back = d.back.Prev()
+	item = unbox[T](d.back.Value)
+	d.back.Value = nil
+	d.len--
+	return item, true
+}
+
+// Front returns the first item without removing it, or reports false if the deque is empty.
+func (d *Deque[T]) Front() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var item T
+	if d.len == 0 {
+		return item, false
+	}
+	return unbox[T](d.front.Value), true
+}
+
+// Back returns the last item without removing it, or reports false if the deque is empty.
+func (d *Deque[T]) Back() (T, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var item T
+	if d.len == 0 {
+		return item, false
+	}
+	return unbox[T](d.back.Prev().Value), true
+}
+
+// Snapshot returns a copy of the items currently in the deque, front to back, without removing them.
+func (d *Deque[T]) Snapshot() []T {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make([]T, 0, d.len)
+	for h, i := d.front, 0; i < d.len; h, i = h.Next(), i+1 {
+		result = append(result, unbox[T](h.Value))
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package deque
+
+import "testing"
+
+func TestDeque(t *testing.T) {
+	d := New[int](2)
+
+	// Enough items from both ends to make the ring grow a couple of times.
+	for i := 0; i < 50; i++ {
+		d.PushBack(i)
+		d.PushFront(-i - 1)
+	}
+	if d.Len() != 100 {
+		t.Fatalf("Expected 100 items, got %d.", d.Len())
+	}
+
+	for i := 50; i > 0; i-- {
+		if item, _ := d.PopFront(); item != -i {
+			t.Fatalf("Expected %d from the front, got %d.", -i, item)
+		}
+	}
+	for i := 49; i >= 0; i-- {
+		if item, _ := d.PopBack(); item != i {
+			t.Fatalf("Expected %d from the back, got %d.", i, item)
+		}
+	}
+	if _, ok := d.PopFront(); ok {
+		t.Fatal("Expected the deque to be empty.")
+	}
+}
+
+func TestPushFrontKeepsOrder(t *testing.T) {
+	d := New[int](4)
+	d.PushBack(3, 4)
+	d.PushFront(1, 2)
+
+	if item, _ := d.Front(); item != 1 {
+		t.Fatalf("Expected 1 at the front, got %d.", item)
+	}
+	if item, _ := d.Back(); item != 4 {
+		t.Fatalf("Expected 4 at the back, got %d.", item)
+	}
+	for i := 1; i <= 4; i++ {
+		if item, _ := d.PopFront(); item != i {
+			t.Fatal("Items missing or reordered.")
+		}
+	}
+}
+
+func TestGrowWhenEmpty(t *testing.T) {
+	d := New[int](2)
+	d.PushBack(1, 2, 3, 4, 5)
+
+	for i := 1; i <= 5; i++ {
+		if item, _ := d.PopFront(); item != i {
+			t.Fatal("Items missing or reordered.")
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	d := New[int](2)
+	d.PushBack(2, 3)
+	d.PushFront(1)
+
+	if s := d.Snapshot(); len(s) != 3 || s[0] != 1 || s[2] != 3 {
+		t.Fatalf("Unexpected snapshot %v.", s)
+	}
+	if d.Len() != 3 {
+		t.Fatal("Snapshot removed items.")
+	}
+}
+
+func TestNilInterfaceItems(t *testing.T) {
+	d := New[error](2)
+	d.PushBack(nil, nil)
+
+	if s := d.Snapshot(); len(s) != 2 || s[0] != nil {
+		t.Fatalf("Unexpected snapshot %v.", s)
+	}
+	if item, ok := d.Front(); !ok || item != nil {
+		t.Fatal("Expected a nil item at the front.")
+	}
+	if item, ok := d.Back(); !ok || item != nil {
+		t.Fatal("Expected a nil item at the back.")
+	}
+	if item, ok := d.PopFront(); !ok || item != nil {
+		t.Fatal("Expected to pop a nil item from the front.")
+	}
+	if item, ok := d.PopBack(); !ok || item != nil {
+		t.Fatal("Expected to pop a nil item from the back.")
+	}
+}
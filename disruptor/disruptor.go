@@ -0,0 +1,232 @@
+// Package disruptor provides a ring of preallocated slots in the style of the LMAX Disruptor,
+// where producers claim and publish slots, and consumers can depend on each other so that
+// several processing stages share one ring without intermediate queues.
+package disruptor
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrClosed is returned by Claim once the ring is closed, and by Wait once the ring is closed
+// and the consumer has processed every published item.
+var ErrClosed = errors.New("disruptor: ring closed")
+
+// Batch is a range of sequence numbers, from Start up to but not including End.
+type Batch struct {
+	Start, End uint64
+}
+
+// Len returns the number of sequence numbers in the batch.
+func (b Batch) Len() int {
+	return int(b.End - b.Start)
+}
+
+// Ring is a fixed-capacity ring of preallocated slots addressed by sequence number,
+// where the slot of sequence number s is s % n.
+type Ring[T any] struct {
+	mu        sync.Mutex
+	slots     []T
+	claimed   uint64 // Sequence number of the next slot to be claimed
+	cursor    uint64 // Sequence number of the first slot not yet published
+	consumers []*Consumer[T]
+	closed    bool
+	changed   chan struct{} // Closed and replaced whenever a sequence number moves
+}
+
+// New creates a Ring of n slots.
+func New[T any](n int) *Ring[T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Ring[T]{
+		slots:   make([]T, n),
+		changed: make(chan struct{}),
+	}
+}
+
+// Cap returns the number of slots in the ring.
+func (r *Ring[T]) Cap() int {
+	return len(r.slots)
+}
+
+// Must be called with mu held.
+func (r *Ring[T]) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Wait for the next change, returning the context's error if ctx is done first.
+// Must be called with mu held.
+func (r *Ring[T]) wait(ctx context.Context) error {
+	changed := r.changed
+	r.mu.Unlock()
+	defer r.mu.Lock()
+
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sequence number of the slowest consumer, or of the next slot to be claimed if there are none.
+// Must be called with mu held.
+func (r *Ring[T]) slowest() uint64 {
+	min := r.claimed
+	for _, c := range r.consumers {
+		if c.next < min {
+			min = c.next
+		}
+	}
+	return min
+}
+
+// Slot returns the slot of the given sequence number. Producers may only write the slots
+// they claimed until they publish them, and consumers may only use the slots of the batches
+// they waited for until they are done with them.
+func (r *Ring[T]) Slot(seq uint64) *T {
+	return &r.slots[seq%uint64(len(r.slots))]
+}
+
+// Claim reserves the next k slots for writing, waiting until every consumer is done with them.
+// k is capped at the capacity of the ring.
+func (r *Ring[T]) Claim(ctx context.Context, k int) (Batch, error) {
+	k = min(k, len(r.slots))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		if r.closed {
+			return Batch{}, ErrClosed
+		}
+		if r.claimed+uint64(k)-r.slowest() <= uint64(len(r.slots)) {
+			break
+		}
+		if err := r.wait(ctx); err != nil {
+			return Batch{}, err
+		}
+	}
+
+	b := Batch{Start: r.claimed, End: r.claimed + uint64(k)}
+	r.claimed = b.End
+	return b, nil
+}
+
+// Publish makes the slots of a claimed batch visible to consumers. Batches are published in
+// the order they were claimed, so Publish waits for every batch claimed before this one.
+func (r *Ring[T]) Publish(b Batch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for r.cursor != b.Start {
+		// A batch claimed earlier is still being written.
+		_ = r.wait(context.Background())
+	}
+	r.cursor = b.End
+	r.notify()
+}
+
+// Close closes the ring for producers. Consumers can still process every published item,
+// after which Wait returns ErrClosed. Close is idempotent.
+func (r *Ring[T]) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.notify()
+}
+
+// NewConsumer attaches a consumer that only sees items once every consumer in deps is done
+// with them. Without deps the consumer sees items as soon as they are published.
+// Consumers should be attached before producers start, as they start at the first unpublished item.
+func (r *Ring[T]) NewConsumer(deps ...*Consumer[T]) *Consumer[T] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := &Consumer[T]{r: r, deps: deps, next: r.cursor}
+	r.consumers = append(r.consumers, c)
+	log.Debugf("Attached consumer with %d dependencies, %d consumers total.", len(deps), len(r.consumers))
+	return c
+}
+
+// Consumer is a processing stage over a Ring. A Consumer must not be used from multiple
+// goroutines concurrently.
+type Consumer[T any] struct {
+	r    *Ring[T]
+	deps []*Consumer[T]
+	next uint64 // Sequence number of the first item not yet processed
+}
+
+// Sequence number of the first item the consumer can't see yet.
+// Must be called with mu held.
+func (c *Consumer[T]) barrier() uint64 {
+	end := c.r.cursor
+	for _, d := range c.deps {
+		if d.next < end {
+			end = d.next
+		}
+	}
+	return end
+}
+
+// Wait returns the batch of items the consumer can process next, blocking until there is at
+// least one. Once the ring is closed and the consumer has processed every published item,
+// Wait returns ErrClosed.
+func (c *Consumer[T]) Wait(ctx context.Context) (Batch, error) {
+	r := c.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		if end := c.barrier(); end > c.next {
+			return Batch{Start: c.next, End: end}, nil
+		}
+		if r.closed && c.next == r.cursor {
+			return Batch{}, ErrClosed
+		}
+		if err := r.wait(ctx); err != nil {
+			return Batch{}, err
+		}
+	}
+}
+
+// Done marks every item before end as processed, making them visible to dependent consumers
+// and their slots available to producers once every consumer is done with them.
+func (c *Consumer[T]) Done(end uint64) {
+	r := c.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if end > c.next {
+		c.next = end
+		r.notify()
+	}
+}
+
+// Run calls fn with every item as it becomes visible to the consumer, until the ring is closed
+// and every published item is processed, or ctx is done.
+func (c *Consumer[T]) Run(ctx context.Context, fn func(seq uint64, item *T)) error {
+	for {
+		b, err := c.Wait(ctx)
+		if errors.Is(err, ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for seq := b.Start; seq < b.End; seq++ {
+			fn(seq, c.r.Slot(seq))
+		}
+		c.Done(b.End)
+	}
+}
@@ -0,0 +1,117 @@
+package disruptor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	r := New[int](4)
+	double := r.NewConsumer()
+	check := r.NewConsumer(double)
+	n := 99
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		err := double.Run(context.Background(), func(_ uint64, item *int) {
+			*item *= 2
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	expected := 0
+	go func() {
+		defer wg.Done()
+		err := check.Run(context.Background(), func(seq uint64, item *int) {
+			// The second stage only sees items the first one is done with.
+			if *item != 2*expected || seq != uint64(expected) {
+				t.Error("Items missing, reordered or unprocessed.")
+			}
+			expected++
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	for i := 0; i < n; i += 3 {
+		b, err := r.Claim(context.Background(), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for seq := b.Start; seq < b.End; seq++ {
+			*r.Slot(seq) = int(seq)
+		}
+		r.Publish(b)
+	}
+	r.Close()
+	wg.Wait()
+
+	if expected != n {
+		t.Fatalf("Expected %d items, got %d.", n, expected)
+	}
+	if _, err := r.Claim(context.Background(), 1); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected claim after close to fail.")
+	}
+}
+
+func TestClaimWaitsForConsumers(t *testing.T) {
+	r := New[int](2)
+	c := r.NewConsumer()
+
+	b, err := r.Claim(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Publish(b)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Claim(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected claim to wait for the consumer.")
+	}
+
+	c.Done(1)
+	if b, err := r.Claim(ctx, 1); err != nil || b.Start != 2 {
+		t.Fatalf("Unexpected claim %v.", b)
+	}
+}
+
+func TestConcurrentProducers(t *testing.T) {
+	r := New[int](8)
+	c := r.NewConsumer()
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				b, err := r.Claim(context.Background(), 1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				*r.Slot(b.Start) = 1
+				r.Publish(b)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		r.Close()
+	}()
+
+	sum := 0
+	if err := c.Run(context.Background(), func(_ uint64, item *int) { sum += *item }); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 200 {
+		t.Fatalf("Expected 200 items, got %d.", sum)
+	}
+}
@@ -0,0 +1,107 @@
+package cirque
+
+import (
+	"context"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// Envelope wraps an item with metadata that travels along with it through a queue:
+// headers, when it was enqueued, its sequence number and the context it was enqueued from,
+// so that consumers can carry on with the same trace.
+type Envelope[T any] struct {
+	Item     T
+	Headers  map[string]string
+	Enqueued time.Time // When the item was wrapped
+	Seq      uint64    // Sequence number in the queue, set by DequeueEnvelopes
+	TraceID  string    // Trace ID of the context the item was wrapped from, if any
+
+	values context.Context // Values of the context the item was wrapped from, without its cancellation
+}
+
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of ctx carrying the trace ID id, which is picked up by Wrap.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceIDFrom returns the trace ID carried by ctx, or an empty string if there is none.
+func TraceIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// Wrap puts item in an Envelope, stamped with the current time and the trace ID and values of ctx.
+// Cancellation of ctx doesn't carry over, as the item outlives the call that enqueued it.
+func Wrap[T any](ctx context.Context, item T) Envelope[T] {
+	return WrapAt(ctx, item, clock.Real.Now())
+}
+
+// WrapAt is like Wrap, but stamps the envelope with now instead of the current time.
+func WrapAt[T any](ctx context.Context, item T, now time.Time) Envelope[T] {
+	return Envelope[T]{
+		Item:     item,
+		Enqueued: now,
+		TraceID:  TraceIDFrom(ctx),
+		values:   context.WithoutCancel(ctx),
+	}
+}
+
+// SetHeader sets the header key to value.
+func (e *Envelope[T]) SetHeader(key, value string) {
+	if e.Headers == nil {
+		e.Headers = make(map[string]string)
+	}
+	e.Headers[key] = value
+}
+
+// Context returns a context with the cancellation of parent and the values of the context
+// the item was wrapped from, including its trace ID, for the consumer to handle the item with.
+// Values of parent are still visible unless the envelope carries the same keys.
+func (e Envelope[T]) Context(parent context.Context) context.Context {
+	ctx := parent
+	if e.values != nil {
+		ctx = valuesContext{Context: parent, values: e.values}
+	}
+	if e.TraceID != "" && TraceIDFrom(ctx) != e.TraceID {
+		ctx = WithTraceID(ctx, e.TraceID)
+	}
+	return ctx
+}
+
+// valuesContext looks up values in a context of its own before falling back to the embedded one.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// EnqueueEnvelopes wraps items like Wrap and adds them to q. Envelopes are stamped with the clock
+// of q, as set with WithClock.
+func EnqueueEnvelopes[T any](ctx context.Context, q *Cirque[Envelope[T]], items ...T) {
+	now := q.clock.Now()
+	envelopes := make([]Envelope[T], len(items))
+	for i, item := range items {
+		envelopes[i] = WrapAt(ctx, item, now)
+	}
+	q.Enqueue(envelopes...)
+}
+
+// DequeueEnvelopes returns a maximum of n envelopes from q, with their sequence numbers set.
+func DequeueEnvelopes[T any](q *Cirque[Envelope[T]], n int) []Envelope[T] {
+	items := q.DequeueSeq(n)
+	result := make([]Envelope[T], len(items))
+	for i, item := range items {
+		result[i] = item.Item
+		result[i].Seq = item.Seq
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package cirque
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+type userKey struct{}
+
+func TestEnvelope(t *testing.T) {
+	q := New[Envelope[int]](4)
+
+	ctx, cancel := context.WithCancel(WithTraceID(context.Background(), "trace-1"))
+	ctx = context.WithValue(ctx, userKey{}, "alice")
+	EnqueueEnvelopes(ctx, q, 1, 2)
+	cancel()
+
+	envelopes := DequeueEnvelopes(q, 10)
+	if len(envelopes) != 2 || envelopes[0].Seq != 1 || envelopes[1].Seq != 2 {
+		t.Fatalf("Expected sequenced envelopes, got %+v.", envelopes)
+	}
+
+	e := envelopes[1]
+	if e.Item != 2 || e.TraceID != "trace-1" || e.Enqueued.IsZero() {
+		t.Fatalf("Unexpected envelope %+v.", e)
+	}
+	restored := e.Context(context.Background())
+	if TraceIDFrom(restored) != "trace-1" || restored.Value(userKey{}) != "alice" {
+		t.Fatal("Expected the context values to be restored.")
+	}
+	if restored.Err() != nil {
+		t.Fatal("Expected the cancellation of the producer not to carry over.")
+	}
+}
+
+func TestEnvelopeFromRemote(t *testing.T) {
+	// Envelopes decoded from elsewhere only carry their fields.
+	e := Envelope[int]{Item: 1, TraceID: "trace-2"}
+	e.SetHeader("origin", "remote")
+
+	if TraceIDFrom(e.Context(context.Background())) != "trace-2" || e.Headers["origin"] != "remote" {
+		t.Fatal("Expected the trace ID and headers to be kept.")
+	}
+}
+
+func TestEnvelopeClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(1000, 0))
+	q := New(4, WithClock[Envelope[int]](c))
+
+	EnqueueEnvelopes(context.Background(), q, 1)
+	c.Advance(time.Minute)
+
+	e := DequeueEnvelopes(q, 1)[0]
+	if age := c.Now().Sub(e.Enqueued); age != time.Minute {
+		t.Fatalf("Expected the envelope to be a minute old, got %v.", age)
+	}
+}
@@ -0,0 +1,133 @@
+// Package errring provides a buffer of recent errors for support bundles and status pages.
+package errring
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// Entry describes an error message seen one or more times.
+type Entry struct {
+	Message string
+	Count   int       // Number of times the message was seen
+	First   time.Time // When the message was first seen
+	Last    time.Time // When the message was last seen
+	Err     error     // The last error with this message
+}
+
+// Ring keeps the last n distinct error messages. Errors with a message that is already in the
+// ring are counted against it and make it the most recent, instead of taking another slot.
+type Ring struct {
+	mu      sync.Mutex
+	n       int
+	order   *list.List               // Entries, most recent first
+	entries map[string]*list.Element // Entries by message
+	clock   clock.Clock
+}
+
+// Option configures a Ring.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the ring timestamp errors with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// New creates a Ring that keeps the last n distinct error messages.
+func New(n int, opts ...Option) *Ring {
+	if n <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Ring{n: n, order: list.New(), entries: make(map[string]*list.Element), clock: cfg.clock}
+}
+
+// Add records err as happening now. Nil errors are ignored.
+func (r *Ring) Add(err error) {
+	r.AddAt(err, r.clock.Now())
+}
+
+// AddAt records err as happening at t. Nil errors are ignored.
+func (r *Ring) AddAt(err error, t time.Time) {
+	if err == nil {
+		return
+	}
+	msg := err.Error()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if el, ok := r.entries[msg]; ok {
+		e := el.Value.(*Entry)
+		e.Count++
+		e.Last = t
+		e.Err = err
+		r.order.MoveToFront(el)
+		return
+	}
+
+	r.entries[msg] = r.order.PushFront(&Entry{Message: msg, Count: 1, First: t, Last: t, Err: err})
+	if r.order.Len() > r.n {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*Entry).Message)
+	}
+}
+
+// Entries returns copies of the entries, most recently seen first.
+func (r *Ring) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]Entry, 0, r.order.Len())
+	for el := r.order.Front(); el != nil; el = el.Next() {
+		result = append(result, *el.Value.(*Entry))
+	}
+	return result
+}
+
+// Len returns the number of distinct messages in the ring.
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
+
+// WriteSummary writes one line per entry to w, most recently seen first.
+func (r *Ring) WriteSummary(w io.Writer) error {
+	for _, e := range r.Entries() {
+		var err error
+		if e.Count == 1 {
+			_, err = fmt.Fprintf(w, "%s %s\n", e.Last.Format(time.RFC3339), e.Message)
+		} else {
+			_, err = fmt.Fprintf(w, "%s %s (x%d since %s)\n",
+				e.Last.Format(time.RFC3339), e.Message, e.Count, e.First.Format(time.RFC3339))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Summary returns the output of WriteSummary as a string.
+func (r *Ring) Summary() string {
+	var b strings.Builder
+	r.WriteSummary(&b)
+	return b.String()
+}
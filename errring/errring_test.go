@@ -0,0 +1,54 @@
+package errring
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestRing(t *testing.T) {
+	r := New(2)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.AddAt(errors.New("timeout"), base)
+	r.AddAt(errors.New("refused"), base.Add(time.Second))
+	r.AddAt(nil, base.Add(2*time.Second))
+	r.AddAt(errors.New("timeout"), base.Add(3*time.Second))
+
+	entries := r.Entries()
+	if len(entries) != 2 || entries[0].Message != "timeout" || entries[0].Count != 2 || !entries[0].First.Equal(base) {
+		t.Fatalf("Unexpected entries %+v.", entries)
+	}
+
+	// "refused" is now the least recent, so it goes first.
+	r.AddAt(errors.New("reset"), base.Add(4*time.Second))
+	entries = r.Entries()
+	if len(entries) != 2 || entries[0].Message != "reset" || entries[1].Message != "timeout" {
+		t.Fatalf("Unexpected entries %+v.", entries)
+	}
+
+	expected := "2024-01-01T00:00:04Z reset\n2024-01-01T00:00:03Z timeout (x2 since 2024-01-01T00:00:00Z)\n"
+	if s := r.Summary(); s != expected {
+		t.Fatalf("Unexpected summary:\n%s", s)
+	}
+	if r.Len() != 2 {
+		t.Fatalf("Expected 2 entries, got %d.", r.Len())
+	}
+}
+
+func TestRingClock(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.NewFake(base)
+	r := New(2, WithClock(c))
+
+	r.Add(errors.New("timeout"))
+	c.Advance(time.Minute)
+	r.Add(errors.New("timeout"))
+
+	entries := r.Entries()
+	if len(entries) != 1 || !entries[0].First.Equal(base) || !entries[0].Last.Equal(base.Add(time.Minute)) {
+		t.Fatalf("Expected entries timestamped by the clock, got %+v.", entries)
+	}
+}
@@ -0,0 +1,96 @@
+package cirque
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Evictor is implemented by queues that can shed their oldest items, so that they can take part in
+// application-level memory management, like reacting to a memory limit being approached.
+type Evictor interface {
+	// EvictOldest removes the oldest items until at least n bytes are freed or nothing is left.
+	EvictOldest(n int64) Eviction
+}
+
+// Eviction describes what EvictOldest dropped.
+type Eviction struct {
+	Items int   // Number of items dropped
+	Bytes int64 // Total size of the items dropped
+}
+
+// WithSizer sets the function EvictOldest uses to tell the size of items, without bounding the queue.
+// Queues with a byte budget use the sizer of the budget.
+func WithSizer[T any](sizer func(T) int64) Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.sizer = sizer
+	}
+}
+
+// OnEvict makes the queue call f with every item dropped by EvictOldest.
+func OnEvict[T any](f func(T)) Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.onEvict = f
+	}
+}
+
+// EvictOldest removes the oldest items until at least n bytes are freed or the queue is empty.
+// It needs item sizes from WithSizer or WithByteBudget, and doesn't evict anything without them.
+func (cq *Cirque[T]) EvictOldest(n int64) Eviction {
+	if cq.sizer == nil {
+		log.Warningf("Tried to evict from a queue without a sizer.")
+		return Eviction{}
+	}
+
+	var e Eviction
+	evicted := cq.dropFront(func(item T, _ time.Time) bool {
+		if e.Bytes >= n {
+			return false
+		}
+		e.Bytes += cq.sizer(item)
+		return true
+	})
+	e.Items = len(evicted)
+	cq.stats.evicted.Add(uint64(e.Items))
+
+	log.Debugf("Evicted %d items of %d bytes.", e.Items, e.Bytes)
+	if cq.onEvict != nil {
+		for _, item := range evicted {
+			cq.onEvict(item)
+		}
+	}
+	return e
+}
+
+// Remove items from the front of the queue for as long as drop returns true, and return them.
+func (cq *Cirque[T]) dropFront(drop func(item T, expires time.Time) bool) []T {
+	cq.readMu.Lock()
+	defer cq.readMu.Unlock()
+
+	end := cq.getWriterHead()
+	h := cq.getReaderHead()
+	var dropped []T
+	var cost int64
+	for ; h != end; h = h.Next() {
+		item, expires := unbox[T](h.Value)
+		if !drop(item, expires) {
+			break
+		}
+		dropped = append(dropped, item)
+		if cq.bound.limit > 0 {
+			cost += cq.bound.cost(item)
+		}
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	cq.setReaderHead(h)
+	cq.len.Add(-int64(len(dropped)))
+	cq.readSeq += uint64(len(dropped))
+
+	if cq.bound.limit > 0 {
+		cq.bound.release(cost)
+	}
+	return dropped
+}
@@ -0,0 +1,40 @@
+package cirque
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEvictOldest(t *testing.T) {
+	var evicted []string
+	cq := New(4,
+		WithByteBudget(100, func(s string) int64 { return int64(len(s)) }),
+		OnEvict(func(s string) { evicted = append(evicted, s) }),
+	)
+	cq.Enqueue("aaaa", "bbbbbb", "cc", "d")
+
+	var _ Evictor = cq
+	e := cq.EvictOldest(5)
+	if e.Items != 2 || e.Bytes != 10 {
+		t.Fatalf("Unexpected eviction %+v.", e)
+	}
+	if !slices.Equal(evicted, []string{"aaaa", "bbbbbb"}) || cq.Stats().Evicted != 2 {
+		t.Fatalf("Expected the oldest items to be reported, got %v.", evicted)
+	}
+	if used, _ := cq.Weight(); used != 3 {
+		t.Fatalf("Expected the budget to be freed, %d bytes in use.", used)
+	}
+
+	// Evicting more than there is empties the queue.
+	if e := cq.EvictOldest(1000); e.Items != 2 || cq.Len() != 0 {
+		t.Fatalf("Unexpected eviction %+v.", e)
+	}
+}
+
+func TestEvictWithoutSizer(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1)
+	if e := cq.EvictOldest(10); e.Items != 0 || cq.Len() != 1 {
+		t.Fatal("Expected nothing to be evicted without a sizer.")
+	}
+}
@@ -0,0 +1,104 @@
+package cirque
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Number of imported items enqueued at once.
+const importBatch = 256
+
+// ExportNDJSON writes the items currently in the queue to w as newline-delimited JSON, one item
+// per line, without removing them. Like with Snapshot, later items are not included.
+func (cq *Cirque[T]) ExportNDJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, item := range cq.Snapshot() {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ImportNDJSON enqueues the items read from r as newline-delimited JSON, and returns how many it read.
+// Items are enqueued in batches as they are read, so on error the items before it are already enqueued.
+func (cq *Cirque[T]) ImportNDJSON(r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	return cq.importItems(func() (T, error) {
+		var item T
+		err := dec.Decode(&item)
+		return item, err
+	})
+}
+
+// ExportCSV writes the items currently in the queue to w as CSV without removing them, with fields
+// mapping every item to a record. Unless header is nil, it is written as the first record.
+func (cq *Cirque[T]) ExportCSV(w io.Writer, header []string, fields func(T) []string) error {
+	cw := csv.NewWriter(w)
+	if header != nil {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	for _, item := range cq.Snapshot() {
+		if err := cw.Write(fields(item)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportCSV enqueues the items read from r as CSV, with parse mapping every record to an item,
+// and returns how many it read. If header is set, the first record is skipped.
+// Like with ImportNDJSON, on error the items before it are already enqueued.
+func (cq *Cirque[T]) ImportCSV(r io.Reader, header bool, parse func([]string) (T, error)) (int, error) {
+	cr := csv.NewReader(r)
+	if header {
+		if _, err := cr.Read(); err != nil {
+			if err == io.EOF {
+				return 0, nil
+			}
+			return 0, err
+		}
+	}
+	return cq.importItems(func() (T, error) {
+		record, err := cr.Read()
+		if err != nil {
+			var item T
+			return item, err
+		}
+		return parse(record)
+	})
+}
+
+// Enqueue items returned by next in batches until it returns io.EOF or another error.
+func (cq *Cirque[T]) importItems(next func() (T, error)) (int, error) {
+	var batch []T
+	total := 0
+	for {
+		item, err := next()
+		if err != nil {
+			cq.Enqueue(batch...)
+			total += len(batch)
+			log.Debugf("Imported %d items.", total)
+			if errors.Is(err, io.EOF) {
+				return total, nil
+			}
+			return total, err
+		}
+
+		batch = append(batch, item)
+		if len(batch) == importBatch {
+			cq.Enqueue(batch...)
+			total += len(batch)
+			batch = batch[:0]
+		}
+	}
+}
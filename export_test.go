@@ -0,0 +1,69 @@
+package cirque
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type job struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestNDJSON(t *testing.T) {
+	cq := New[job](2)
+	cq.Enqueue(job{1, "a"}, job{2, "b"})
+
+	var buf bytes.Buffer
+	if err := cq.ExportNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n" {
+		t.Fatalf("Unexpected export %q.", buf.String())
+	}
+	if cq.Len() != 2 {
+		t.Fatal("Export consumed items.")
+	}
+
+	imported := New[job](2)
+	if n, err := imported.ImportNDJSON(&buf); err != nil || n != 2 {
+		t.Fatalf("Imported %d items: %v.", n, err)
+	}
+	if items := imported.Dequeue(2); items[0] != (job{1, "a"}) || items[1] != (job{2, "b"}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+
+	if n, err := imported.ImportNDJSON(strings.NewReader("{\"id\":3}\nnot json\n")); err == nil || n != 1 {
+		t.Fatal("Expected the items before invalid input to be imported.")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	cq := New[job](2)
+	cq.Enqueue(job{1, "a,b"}, job{2, "c"})
+
+	var buf bytes.Buffer
+	err := cq.ExportCSV(&buf, []string{"id", "name"}, func(j job) []string {
+		return []string{strconv.Itoa(j.ID), j.Name}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "id,name\n1,\"a,b\"\n2,c\n" {
+		t.Fatalf("Unexpected export %q.", buf.String())
+	}
+
+	imported := New[job](2)
+	n, err := imported.ImportCSV(&buf, true, func(record []string) (job, error) {
+		id, err := strconv.Atoi(record[0])
+		return job{id, record[1]}, err
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("Imported %d items: %v.", n, err)
+	}
+	if items := imported.Dequeue(2); items[0] != (job{1, "a,b"}) || items[1] != (job{2, "c"}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
@@ -0,0 +1,12 @@
+package cirque
+
+// Fold combines the items in q, in order, into a single value, starting from init and calling
+// fn with the value so far and every item. It operates on a snapshot of the queue, so summaries
+// like the total size of pending items can be computed without dequeueing them.
+func Fold[T, A any](q *Cirque[T], init A, fn func(A, T) A) A {
+	acc := init
+	for _, item := range q.peek(-1) {
+		acc = fn(acc, item)
+	}
+	return acc
+}
@@ -0,0 +1,25 @@
+package cirque
+
+import "testing"
+
+func TestFold(t *testing.T) {
+	cq := New[string](4)
+	cq.Enqueue("a", "bb", "a", "ccc")
+
+	total := Fold(cq, 0, func(n int, s string) int { return n + len(s) })
+	if total != 7 {
+		t.Fatalf("Expected a total length of 7, got %d.", total)
+	}
+
+	counts := Fold(cq, map[string]int{}, func(m map[string]int, s string) map[string]int {
+		m[s]++
+		return m
+	})
+	if counts["a"] != 2 || len(counts) != 3 {
+		t.Fatalf("Unexpected counts %v.", counts)
+	}
+
+	if cq.Len() != 4 {
+		t.Fatal("Expected folding to leave the items in the queue.")
+	}
+}
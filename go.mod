@@ -1,6 +1,6 @@
 module github.com/denis-ismailaj/cirque
 
-go 1.18
+go 1.23
 
 require github.com/sirupsen/logrus v1.8.1
 
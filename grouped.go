@@ -0,0 +1,14 @@
+package cirque
+
+// DequeueGrouped returns a maximum of n items from q like Dequeue, grouped into batches by the key
+// returned by key. Items keep their order within a batch, so consumers that write items per
+// destination, like per-shard database writes, get them ready to send.
+// Methods can't have type parameters of their own, which is why this isn't a method of Cirque.
+func DequeueGrouped[T any, K comparable](q *Cirque[T], n int, key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	q.take(n, func(_ uint64, item T, _ any) {
+		k := key(item)
+		groups[k] = append(groups[k], item)
+	})
+	return groups
+}
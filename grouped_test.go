@@ -0,0 +1,23 @@
+package cirque
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDequeueGrouped(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2, 3, 4, 5, 6, 7)
+
+	groups := DequeueGrouped(cq, 6, func(i int) bool { return i%2 == 0 })
+	if !slices.Equal(groups[true], []int{2, 4, 6}) || !slices.Equal(groups[false], []int{1, 3, 5}) {
+		t.Fatalf("Unexpected groups %v.", groups)
+	}
+	if cq.Len() != 1 {
+		t.Fatalf("Expected 1 item left, got %d.", cq.Len())
+	}
+
+	if groups := DequeueGrouped(cq, 0, func(i int) int { return i }); len(groups) != 0 {
+		t.Fatal("Expected no groups.")
+	}
+}
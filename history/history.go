@@ -0,0 +1,89 @@
+// Package history provides a buffer of the most recent items appended to it.
+package history
+
+import "sync"
+
+// Buffer always retains the last n appended items, overwriting the oldest ones.
+// The item appended i-th is stored at slots[i % n], so all lookups are O(1).
+type Buffer[T any] struct {
+	mu    sync.RWMutex
+	slots []T
+	count uint64 // Number of items appended so far
+}
+
+// New creates a Buffer that retains the last n items.
+func New[T any](n int) *Buffer[T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Buffer[T]{slots: make([]T, n)}
+}
+
+// Append adds items, overwriting the oldest ones if the buffer is full.
+func (b *Buffer[T]) Append(items ...T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, item := range items {
+		b.slots[b.count%uint64(len(b.slots))] = item
+		b.count++
+	}
+}
+
+// Len returns the number of retained items.
+func (b *Buffer[T]) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return int(min(b.count, uint64(len(b.slots))))
+}
+
+// Cap returns the number of items the buffer retains.
+func (b *Buffer[T]) Cap() int {
+	return len(b.slots)
+}
+
+// Total returns the number of items appended so far, including overwritten ones.
+func (b *Buffer[T]) Total() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.count
+}
+
+// Latest returns the most recently appended item, or reports false if the buffer is empty.
+func (b *Buffer[T]) Latest() (T, bool) {
+	return b.NthNewest(0)
+}
+
+// NthNewest returns the item appended i items before the latest one, so NthNewest(0) is
+// the latest item. It reports false if that item isn't retained.
+func (b *Buffer[T]) NthNewest(i int) (T, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if i < 0 || uint64(i) >= min(b.count, uint64(len(b.slots))) {
+		var zero T
+		return zero, false
+	}
+	return b.slots[(b.count-1-uint64(i))%uint64(len(b.slots))], true
+}
+
+// Snapshot returns a copy of the retained items, oldest first.
+func (b *Buffer[T]) Snapshot() []T {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.count <= uint64(len(b.slots)) {
+		return append([]T(nil), b.slots[:b.count]...)
+	}
+	i := b.count % uint64(len(b.slots))
+	return append(append([]T(nil), b.slots[i:]...), b.slots[:i]...)
+}
+
+// Reset removes all retained items.
+func (b *Buffer[T]) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.slots)
+	b.count = 0
+}
@@ -0,0 +1,36 @@
+package history
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	b := New[int](3)
+
+	if _, ok := b.Latest(); ok {
+		t.Fatal("Expected no latest item in an empty buffer.")
+	}
+
+	b.Append(1, 2)
+	if !slices.Equal(b.Snapshot(), []int{1, 2}) {
+		t.Fatalf("Unexpected snapshot %v.", b.Snapshot())
+	}
+
+	b.Append(3, 4, 5)
+	if !slices.Equal(b.Snapshot(), []int{3, 4, 5}) {
+		t.Fatalf("Unexpected snapshot %v.", b.Snapshot())
+	}
+	if latest, _ := b.Latest(); latest != 5 {
+		t.Fatalf("Expected latest 5, got %d.", latest)
+	}
+	if item, _ := b.NthNewest(2); item != 3 {
+		t.Fatalf("Expected 3, got %d.", item)
+	}
+	if _, ok := b.NthNewest(3); ok {
+		t.Fatal("Expected overwritten item to be unavailable.")
+	}
+	if b.Len() != 3 || b.Total() != 5 {
+		t.Fatal("Unexpected lengths.")
+	}
+}
@@ -0,0 +1,38 @@
+package cirque
+
+// Point is a place in the reader and writer protocol of a Cirque where a test harness can
+// interleave goroutines, for checking interleavings deterministically instead of hoping that
+// the race detector comes across them.
+//
+// Hooks are only called in builds with the cirque_hooks build tag, which provide SetHook to
+// install one. In other builds they compile away.
+type Point int
+
+const (
+	// LoadReadHead is reached right before the reader head is loaded.
+	LoadReadHead Point = iota
+	// LoadWriteHead is reached right before the writer head is loaded.
+	LoadWriteHead
+	// StoreReadHead is reached right before the reader head is moved.
+	StoreReadHead
+	// StoreWriteHead is reached right before the writer head is moved, publishing written items.
+	StoreWriteHead
+	// Wait is reached right before a goroutine blocks waiting for items or for room.
+	Wait
+)
+
+func (p Point) String() string {
+	switch p {
+	case LoadReadHead:
+		return "LoadReadHead"
+	case LoadWriteHead:
+		return "LoadWriteHead"
+	case StoreReadHead:
+		return "StoreReadHead"
+	case StoreWriteHead:
+		return "StoreWriteHead"
+	case Wait:
+		return "Wait"
+	}
+	return "Point(?)"
+}
@@ -0,0 +1,25 @@
+//go:build cirque_hooks
+
+package cirque
+
+import "sync/atomic"
+
+var hook atomic.Pointer[func(Point)]
+
+// SetHook makes every queue call f whenever a goroutine reaches a Point, on that goroutine.
+// f can block to hold the goroutine back while others make progress. A nil f removes the hook.
+// SetHook is only available in builds with the cirque_hooks build tag.
+func SetHook(f func(Point)) {
+	if f == nil {
+		hook.Store(nil)
+		return
+	}
+	hook.Store(&f)
+}
+
+// Call the hook, if any, with p.
+func reach(p Point) {
+	if f := hook.Load(); f != nil {
+		(*f)(p)
+	}
+}
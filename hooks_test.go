@@ -0,0 +1,57 @@
+//go:build cirque_hooks
+
+package cirque
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHookPoints(t *testing.T) {
+	var points []Point
+	SetHook(func(p Point) { points = append(points, p) })
+	defer SetHook(nil)
+
+	cq := New[int](4)
+	cq.Enqueue(1)
+	cq.Dequeue(1)
+
+	expected := []Point{LoadWriteHead, StoreWriteHead, LoadWriteHead, LoadReadHead, StoreReadHead}
+	if !slices.Equal(points, expected) {
+		t.Fatalf("Expected points %v, got %v.", expected, points)
+	}
+}
+
+func TestHookInterleaving(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1)
+
+	// Hold the reader back right after it loaded the writer head.
+	var armed atomic.Bool
+	armed.Store(true)
+	paused, resume := make(chan struct{}), make(chan struct{})
+	SetHook(func(p Point) {
+		if p == LoadReadHead && armed.CompareAndSwap(true, false) {
+			close(paused)
+			<-resume
+		}
+	})
+	defer SetHook(nil)
+
+	result := make(chan []int)
+	go func() {
+		result <- cq.Dequeue(10)
+	}()
+
+	// Items published while the reader is held back aren't part of its read.
+	<-paused
+	cq.Enqueue(2)
+	close(resume)
+	if items := <-result; !slices.Equal(items, []int{1}) {
+		t.Fatalf("Expected only the item published before the read, got %v.", items)
+	}
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{2}) {
+		t.Fatalf("Expected the item published during the read, got %v.", items)
+	}
+}
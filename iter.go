@@ -0,0 +1,64 @@
+package cirque
+
+import (
+	"context"
+	"iter"
+)
+
+// Drain returns an iterator that dequeues items one by one as they arrive, blocking while
+// the queue is empty. The iteration ends when the queue is closed and empty, or when ctx is done.
+func (cq *Cirque[T]) Drain(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			items, err := cq.DequeueWait(ctx, 1)
+			if err != nil || !yield(items[0]) {
+				return
+			}
+		}
+	}
+}
+
+// ChunkOption configures the iterator returned by Chunks.
+type ChunkOption func(*chunkConfig)
+
+type chunkConfig struct {
+	ctx context.Context // Context to wait for items with, or nil to stop at an empty queue
+}
+
+// WaitForItems makes Chunks wait for items to fill every chunk, until the queue is closed or ctx
+// is done, instead of stopping once the queue is empty.
+func WaitForItems(ctx context.Context) ChunkOption {
+	return func(c *chunkConfig) {
+		c.ctx = ctx
+	}
+}
+
+// Chunks returns an iterator that dequeues items in chunks of size, of which only the last one may
+// be shorter. By default the iteration ends once the queue is empty; see WaitForItems for waiting.
+// Items dequeued for a chunk are always yielded, even if the iteration ends before it is full.
+func (cq *Cirque[T]) Chunks(size int, opts ...ChunkOption) iter.Seq[[]T] {
+	var cfg chunkConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(yield func([]T) bool) {
+		if size <= 0 {
+			return
+		}
+		for {
+			chunk := cq.Dequeue(size)
+			for cfg.ctx != nil && len(chunk) < size {
+				items, err := cq.DequeueWait(cfg.ctx, size-len(chunk))
+				if err != nil {
+					break
+				}
+				chunk = append(chunk, items...)
+			}
+
+			if len(chunk) == 0 || !yield(chunk) || len(chunk) < size {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cirque
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	cq := New[int](4)
+	n := 100
+
+	go func() {
+		for i := 0; i < n; i++ {
+			cq.Enqueue(i)
+		}
+		cq.Close()
+	}()
+
+	expected := 0
+	for item := range cq.Drain(context.Background()) {
+		if item != expected {
+			t.Fatal("Items missing or reordered.")
+		}
+		expected++
+	}
+	if expected != n {
+		t.Fatalf("Expected %d items, got %d.", n, expected)
+	}
+}
+
+func TestDrainCancelled(t *testing.T) {
+	cq := New[int](4)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	for range cq.Drain(ctx) {
+		t.Fatal("Expected no items.")
+	}
+}
+
+func TestChunks(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2, 3, 4, 5)
+
+	var chunks [][]int
+	for chunk := range cq.Chunks(2) {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 3 || !slices.Equal(chunks[2], []int{5}) {
+		t.Fatalf("Unexpected chunks %v.", chunks)
+	}
+}
+
+func TestChunksWait(t *testing.T) {
+	cq := New[int](4)
+	go func() {
+		for i := 0; i < 5; i++ {
+			cq.Enqueue(i)
+			time.Sleep(time.Millisecond)
+		}
+		cq.Close()
+	}()
+
+	var chunks [][]int
+	for chunk := range cq.Chunks(2, WaitForItems(context.Background())) {
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 3 || len(chunks[0]) != 2 || len(chunks[1]) != 2 || !slices.Equal(chunks[2], []int{4}) {
+		t.Fatalf("Expected full chunks until the queue closed, got %v.", chunks)
+	}
+}
@@ -0,0 +1,177 @@
+// Package jitter provides a jitter buffer that plays out sequence-numbered items at a steady pace.
+package jitter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// Stats holds cumulative counters of a Buffer.
+type Stats struct {
+	Received uint64 // Items accepted into the buffer
+	Played   uint64 // Items released at their playout time
+	Missing  uint64 // Playout slots whose item never arrived in time
+	Late     uint64 // Items dropped because their playout slot had already passed
+	Dropped  uint64 // Items dropped because they were too far ahead of playout, or duplicates
+}
+
+// Buffer absorbs variance in arrival times by holding back items and releasing one per
+// interval in sequence order, like the jitter buffer of an RTP audio receiver.
+//
+// Playout starts once depth items are buffered, with the lowest sequence number received
+// so far. Items live in a ring of 2*depth slots, where the item with sequence number s
+// is stored in slot s % len(slots).
+type Buffer[T any] struct {
+	mu       sync.Mutex
+	slots    []slot[T]
+	depth    int
+	interval time.Duration
+	started  bool
+	first    uint64        // Lowest sequence number received before playout started
+	last     uint64        // Highest sequence number received before playout started
+	next     uint64        // Sequence number of the next item to play out
+	start    time.Time     // Playout time of the first item
+	buffered int           // Number of items in slots
+	ready    chan struct{} // Closed when playout starts
+	stats    Stats
+	clock    clock.Clock
+}
+
+// Option configures a Buffer.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the buffer pace playout with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+type slot[T any] struct {
+	item T
+	ok   bool
+}
+
+// New creates a Buffer that starts playout after depth items and then releases one item per interval.
+func New[T any](depth int, interval time.Duration, opts ...Option) *Buffer[T] {
+	if depth <= 0 || interval <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Buffer[T]{
+		slots:    make([]slot[T], 2*depth),
+		depth:    depth,
+		interval: interval,
+		ready:    make(chan struct{}),
+		clock:    cfg.clock,
+	}
+}
+
+// Push adds item with sequence number seq. Items whose playout time has passed, and items
+// too far ahead of the playout position, are dropped and counted in Stats.
+func (b *Buffer[T]) Push(seq uint64, item T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.started && seq < b.next {
+		b.stats.Late++
+		return
+	}
+
+	// Before playout starts, the window begins at the lowest sequence number received.
+	base, last := b.next, seq
+	if !b.started && b.buffered > 0 {
+		base, last = min(b.first, seq), max(b.last, seq)
+	} else if !b.started {
+		base = seq
+	}
+	if last-base >= uint64(len(b.slots)) {
+		b.stats.Dropped++
+		return
+	}
+
+	s := &b.slots[seq%uint64(len(b.slots))]
+	if s.ok {
+		b.stats.Dropped++
+		return
+	}
+	s.item, s.ok = item, true
+	b.buffered++
+	b.stats.Received++
+	if !b.started {
+		b.first, b.last = base, last
+	}
+
+	if !b.started && b.buffered >= b.depth {
+		b.started = true
+		b.next = b.first
+		b.start = b.clock.Now()
+		close(b.ready)
+	}
+}
+
+// Next waits for the next playout time and returns the item scheduled for it.
+// If that item never arrived, Next reports false so the caller can conceal the loss.
+// It returns the context's error if ctx is done first.
+func (b *Buffer[T]) Next(ctx context.Context) (T, bool, error) {
+	var zero T
+
+	select {
+	case <-b.ready:
+	case <-ctx.Done():
+		return zero, false, ctx.Err()
+	}
+
+	b.mu.Lock()
+	due := b.start.Add(time.Duration(b.stats.Played+b.stats.Missing) * b.interval)
+	b.mu.Unlock()
+
+	if wait := due.Sub(b.clock.Now()); wait > 0 {
+		tick, timer := clock.After(b.clock, wait)
+		defer timer.Stop()
+		select {
+		case <-tick:
+		case <-ctx.Done():
+			return zero, false, ctx.Err()
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &b.slots[b.next%uint64(len(b.slots))]
+	item, ok := s.item, s.ok
+	*s = slot[T]{}
+	b.next++
+	if ok {
+		b.buffered--
+		b.stats.Played++
+	} else {
+		b.stats.Missing++
+	}
+	return item, ok, nil
+}
+
+// Len returns the number of buffered items.
+func (b *Buffer[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffered
+}
+
+// Stats returns the counters of the buffer.
+func (b *Buffer[T]) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
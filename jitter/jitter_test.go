@@ -0,0 +1,86 @@
+package jitter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestJitter(t *testing.T) {
+	interval := 5 * time.Millisecond
+	b := New[int](3, interval)
+
+	// Out of order arrival, with sequence number 3 missing.
+	for _, seq := range []uint64{1, 0, 2, 5, 4} {
+		b.Push(seq, int(seq))
+	}
+
+	start := time.Now()
+	for seq := 0; seq < 6; seq++ {
+		item, ok, err := b.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq == 3 {
+			if ok {
+				t.Fatal("Expected sequence number 3 to be missing.")
+			}
+			// Arrives after its playout time.
+			b.Push(3, 3)
+			continue
+		}
+		if !ok || item != seq {
+			t.Fatalf("Expected %d, got %d, %v.", seq, item, ok)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 5*interval {
+		t.Fatalf("Items played out too fast: %v.", elapsed)
+	}
+
+	s := b.Stats()
+	if s.Played != 5 || s.Missing != 1 || s.Late != 1 {
+		t.Fatalf("Unexpected stats %+v.", s)
+	}
+}
+
+func TestWaitsForDepth(t *testing.T) {
+	b := New[int](2, time.Millisecond)
+	b.Push(0, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := b.Next(ctx); err == nil {
+		t.Fatal("Expected playout not to start before the buffer is deep enough.")
+	}
+}
+
+func TestJitterClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	b := New[int](2, time.Minute, WithClock(c))
+	b.Push(0, 0)
+	b.Push(1, 1)
+
+	if item, ok, err := b.Next(context.Background()); err != nil || !ok || item != 0 {
+		t.Fatalf("Expected the first item right away, got %d, %v, %v.", item, ok, err)
+	}
+
+	next := make(chan int)
+	go func() {
+		item, _, _ := b.Next(context.Background())
+		next <- item
+	}()
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-next:
+		t.Fatal("Expected the second item to wait for its playout time.")
+	default:
+	}
+	c.Advance(time.Minute)
+	if item := <-next; item != 1 {
+		t.Fatalf("Expected the second item, got %d.", item)
+	}
+}
@@ -0,0 +1,65 @@
+package keyed
+
+import (
+	"sync"
+
+	"github.com/denis-ismailaj/cirque/deque"
+)
+
+// Compacting is a FIFO queue that holds at most one pending item per key. Enqueueing an item
+// for a key that already has a pending item replaces that item in place, keeping its position,
+// so only the latest value per key is ever dequeued and memory is bounded by the number of keys.
+type Compacting[K comparable, T any] struct {
+	mu      sync.Mutex
+	order   *deque.Deque[K] // Keys with pending items, in FIFO order
+	pending map[K]T         // Latest pending item of every key in order
+}
+
+// NewCompacting creates a Compacting queue of initial size n.
+func NewCompacting[K comparable, T any](n int) *Compacting[K, T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Compacting[K, T]{
+		order:   deque.New[K](n),
+		pending: make(map[K]T),
+	}
+}
+
+// Enqueue adds item for key, replacing the pending item of key if there is one.
+// It reports whether an item was replaced.
+func (q *Compacting[K, T]) Enqueue(key K, item T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, replaced := q.pending[key]
+	if !replaced {
+		q.order.PushBack(key)
+	}
+	q.pending[key] = item
+	return replaced
+}
+
+// Dequeue returns a maximum of n items, in the order their keys were first enqueued.
+func (q *Compacting[K, T]) Dequeue(n int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []T
+	for len(result) < n {
+		key, ok := q.order.PopFront()
+		if !ok {
+			break
+		}
+		result = append(result, q.pending[key])
+		delete(q.pending, key)
+	}
+	return result
+}
+
+// Len returns the number of pending items, which is also the number of keys that have one.
+func (q *Compacting[K, T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
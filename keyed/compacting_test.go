@@ -0,0 +1,32 @@
+package keyed
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompacting(t *testing.T) {
+	q := NewCompacting[string, string](2)
+
+	q.Enqueue("dev1", "dev1: booting")
+	q.Enqueue("dev2", "dev2: booting")
+	if !q.Enqueue("dev1", "dev1: online") {
+		t.Fatal("Expected the pending item of dev1 to be replaced.")
+	}
+	q.Enqueue("dev3", "dev3: offline")
+
+	if q.Len() != 3 {
+		t.Fatalf("Expected 3 pending items, got %d.", q.Len())
+	}
+
+	// dev1 keeps its original position, but with its latest value.
+	expected := []string{"dev1: online", "dev2: booting", "dev3: offline"}
+	if items := q.Dequeue(10); !slices.Equal(items, expected) {
+		t.Fatalf("Expected %v, got %v.", expected, items)
+	}
+
+	// Once dequeued, a key starts over at the back.
+	if q.Enqueue("dev1", "dev1: offline") {
+		t.Fatal("Expected no pending item to be replaced.")
+	}
+}
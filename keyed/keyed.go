@@ -0,0 +1,102 @@
+// Package keyed provides queues that keep items of different keys apart.
+package keyed
+
+import (
+	"sync"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/deque"
+)
+
+// Queue keeps a separate FIFO queue per key and dequeues from the keys in round-robin,
+// so that a key with a large backlog can't starve the others. Items of the same key keep
+// their order.
+type Queue[K comparable, T any] struct {
+	mu     sync.Mutex
+	n      int                     // Initial size of every per-key queue
+	queues map[K]*cirque.Cirque[T] // Queues of keys that have items
+	active *deque.Deque[K]         // Keys with items, in the order they will be served
+	len    int                     // Number of items across all keys
+}
+
+// New creates a Queue where every per-key queue has initial size n.
+func New[K comparable, T any](n int) *Queue[K, T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Queue[K, T]{
+		n:      n,
+		queues: make(map[K]*cirque.Cirque[T]),
+		active: deque.New[K](n),
+	}
+}
+
+// Enqueue adds items to the queue of key.
+func (q *Queue[K, T]) Enqueue(key K, items ...T) {
+	if len(items) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cq, ok := q.queues[key]
+	if !ok {
+		cq = cirque.New[T](q.n)
+		q.queues[key] = cq
+		// The key goes to the back of the line.
+		q.active.PushBack(key)
+	}
+	cq.Enqueue(items...)
+	q.len += len(items)
+}
+
+// Dequeue returns a maximum of n items, taking one item at a time from every key that has items.
+func (q *Queue[K, T]) Dequeue(n int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []T
+	for len(result) < n {
+		key, ok := q.active.PopFront()
+		if !ok {
+			break
+		}
+		cq := q.queues[key]
+		result = append(result, cq.Dequeue(1)...)
+
+		if cq.Len() > 0 {
+			q.active.PushBack(key)
+		} else {
+			// Forget keys without items, so that memory is bounded by the active keys.
+			delete(q.queues, key)
+		}
+	}
+	q.len -= len(result)
+	return result
+}
+
+// Len returns the number of items across all keys.
+func (q *Queue[K, T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.len
+}
+
+// KeyLen returns the number of items of key.
+func (q *Queue[K, T]) KeyLen(key K) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if cq, ok := q.queues[key]; ok {
+		return cq.Len()
+	}
+	return 0
+}
+
+// Keys returns the number of keys that have items.
+func (q *Queue[K, T]) Keys() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queues)
+}
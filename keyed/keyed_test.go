@@ -0,0 +1,31 @@
+package keyed
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRoundRobin(t *testing.T) {
+	q := New[string, int](2)
+
+	// A noisy tenant with a large backlog, and two quiet ones.
+	q.Enqueue("noisy", 1, 2, 3, 4, 5, 6)
+	q.Enqueue("a", 10, 11)
+	q.Enqueue("b", 20)
+
+	if q.Len() != 9 || q.Keys() != 3 || q.KeyLen("a") != 2 {
+		t.Fatal("Unexpected lengths.")
+	}
+
+	items := q.Dequeue(6)
+	if expected := []int{1, 10, 20, 2, 11, 3}; !slices.Equal(items, expected) {
+		t.Fatalf("Expected %v, got %v.", expected, items)
+	}
+
+	if items := q.Dequeue(10); !slices.Equal(items, []int{4, 5, 6}) {
+		t.Fatalf("Unexpected remaining items %v.", items)
+	}
+	if q.Len() != 0 || q.Keys() != 0 {
+		t.Fatal("Expected the queue to be empty.")
+	}
+}
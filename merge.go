@@ -0,0 +1,30 @@
+package cirque
+
+import (
+	"cmp"
+	"iter"
+)
+
+// MergeSorted returns an iterator that dequeues items from a and b, which must each be sorted,
+// as a single sorted stream. Items are dequeued lazily, one at a time as the iteration proceeds,
+// and ties go to a. The iteration ends once both queues are empty, so it suits combining
+// time-ordered events of several sources once they are in. It must be the only consumer of a and b.
+func MergeSorted[T cmp.Ordered](a, b *Cirque[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			next := a
+			x, y := a.Peek(1), b.Peek(1)
+			switch {
+			case len(x) == 0 && len(y) == 0:
+				return
+			case len(x) == 0, len(y) > 0 && y[0] < x[0]:
+				next = b
+			}
+
+			items := next.Dequeue(1)
+			if len(items) > 0 && !yield(items[0]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package cirque
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeSorted(t *testing.T) {
+	a, b := New[int](4), New[int](4)
+	a.Enqueue(1, 4, 5, 9)
+	b.Enqueue(2, 3, 5, 10, 11)
+
+	var merged []int
+	for item := range MergeSorted(a, b) {
+		merged = append(merged, item)
+		if item == 9 {
+			break
+		}
+	}
+	if !slices.Equal(merged, []int{1, 2, 3, 4, 5, 5, 9}) {
+		t.Fatalf("Unexpected merge %v.", merged)
+	}
+	if !slices.Equal(b.Snapshot(), []int{10, 11}) {
+		t.Fatal("Expected items past the end of the iteration to stay in their queue.")
+	}
+}
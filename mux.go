@@ -0,0 +1,148 @@
+package cirque
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Mux dequeues from several queues into one consumer, serving them in round-robin one item
+// at a time, so that a queue with a large backlog can't starve the others.
+// It is safe to use a Mux from multiple goroutines, like the workers of a shared pool.
+//
+// Queues can be given weights, in which case every turn of a queue serves up to as many items
+// as its weight. A queue with items never waits for more than the sum of the other weights,
+// so even a queue of the lowest weight can't be starved by the heavier ones.
+type Mux[T any] struct {
+	mu      sync.Mutex
+	queues  []*Cirque[T]
+	weights []int // Weight of the queue with the same index
+	next    int   // Index of the queue to serve next
+	served  int   // Number of items served in the current turn of the next queue
+}
+
+// NewMux creates a Mux over the given queues, all with weight 1.
+func NewMux[T any](queues ...*Cirque[T]) *Mux[T] {
+	if len(queues) == 0 {
+		return nil
+	}
+	m := &Mux[T]{
+		queues:  queues,
+		weights: make([]int, len(queues)),
+	}
+	for i := range m.weights {
+		m.weights[i] = 1
+	}
+	return m
+}
+
+// Len returns the number of items across all queues.
+func (m *Mux[T]) Len() int {
+	n := 0
+	for _, q := range m.queues {
+		n += q.Len()
+	}
+	return n
+}
+
+// SetWeight changes the weight of q, which must be one of the queues of the Mux.
+// Weights below 1 are raised to 1.
+func (m *Mux[T]) SetWeight(q *Cirque[T], weight int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.queues {
+		if m.queues[i] == q {
+			m.weights[i] = max(weight, 1)
+			return
+		}
+	}
+	log.Warningf("Tried to set weight of a queue that isn't part of the mux.")
+}
+
+// End the turn of the next queue.
+// Must be called with mu held.
+func (m *Mux[T]) advance() {
+	m.next = (m.next + 1) % len(m.queues)
+	m.served = 0
+}
+
+// Dequeue returns a maximum of n items from the queues, without waiting.
+func (m *Mux[T]) Dequeue(n int) []T {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []T
+	// Stop once every queue in a row turned out empty.
+	for empty := 0; len(result) < n && empty < len(m.queues); {
+		items := m.queues[m.next].Dequeue(1)
+		if len(items) == 0 {
+			empty++
+			m.advance()
+			continue
+		}
+		empty = 0
+		result = append(result, items[0])
+
+		m.served++
+		if m.served >= m.weights[m.next] {
+			m.advance()
+		}
+	}
+
+	log.Debugf("Multiplexed %d items.", len(result))
+	return result
+}
+
+// Drain returns an iterator that dequeues items one by one as they arrive in any of the queues,
+// blocking while all of them are empty. The iteration ends when every queue is closed and empty,
+// or when ctx is done. Waiting doesn't take wakeups away from DequeueWait on the queues, so they
+// can have other consumers.
+func (m *Mux[T]) Drain(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		// Queues that haven't been closed yet
+		open := make([]*Cirque[T], len(m.queues))
+		copy(open, m.queues)
+
+		for {
+			if items := m.Dequeue(1); len(items) > 0 {
+				if !yield(items[0]) {
+					return
+				}
+				continue
+			}
+			if len(open) == 0 {
+				// Items may have been enqueued right before closing.
+				if m.Len() == 0 {
+					return
+				}
+				continue
+			}
+
+			// Watch for arrivals before looking again, so that none slips in between.
+			cases := make([]reflect.SelectCase, 0, 2*len(open)+1)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+			for _, q := range open {
+				cases = append(cases,
+					reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(q.arrival())},
+					reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(q.done)},
+				)
+			}
+			if m.Len() > 0 {
+				continue
+			}
+
+			reach(Wait)
+			chosen, _, _ := reflect.Select(cases)
+			if chosen == 0 {
+				return
+			}
+			if i := (chosen - 1) / 2; (chosen-1)%2 == 1 {
+				open = append(open[:i], open[i+1:]...)
+			}
+		}
+	}
+}
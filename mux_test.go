@@ -0,0 +1,114 @@
+package cirque
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMuxRoundRobin(t *testing.T) {
+	a := New[string](4)
+	b := New[string](4)
+	c := New[string](4)
+	a.Enqueue("a1", "a2", "a3", "a4")
+	b.Enqueue("b1")
+	c.Enqueue("c1", "c2")
+
+	m := NewMux(a, b, c)
+	items := m.Dequeue(10)
+	if !slices.Equal(items, []string{"a1", "b1", "c1", "a2", "c2", "a3", "a4"}) {
+		t.Fatalf("Items not served fairly: %v.", items)
+	}
+	if m.Len() != 0 {
+		t.Fatal("Expected all queues to be drained.")
+	}
+}
+
+func TestMuxDrain(t *testing.T) {
+	a := New[int](4)
+	b := New[int](4)
+	m := NewMux(a, b)
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			if i%2 == 0 {
+				a.Enqueue(i)
+			} else {
+				b.Enqueue(i)
+			}
+		}
+		a.Close()
+		b.Close()
+	}()
+
+	sum := 0
+	for item := range m.Drain(context.Background()) {
+		sum += item
+	}
+	if sum != 4950 {
+		t.Fatal("Items missing.")
+	}
+}
+
+func TestMuxWeights(t *testing.T) {
+	heavy := New[string](8)
+	light := New[string](8)
+	for i := 0; i < 8; i++ {
+		heavy.Enqueue("h")
+		light.Enqueue("l")
+	}
+
+	m := NewMux(heavy, light)
+	m.SetWeight(heavy, 3)
+
+	// The light queue is served once in every 4 items, and never waits for more than 3.
+	items := m.Dequeue(8)
+	if !slices.Equal(items, []string{"h", "h", "h", "l", "h", "h", "h", "l"}) {
+		t.Fatalf("Items not served by weight: %v.", items)
+	}
+}
+
+func TestMuxDrainAlongsideDequeueWait(t *testing.T) {
+	a := New[int](4)
+	b := New[int](4)
+	m := NewMux(a, b)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Another consumer waits on one of the queues while the mux drains both.
+	waited := make(chan int)
+	go func() {
+		sum := 0
+		for {
+			items, err := a.DequeueWait(ctx, 1)
+			if err != nil {
+				waited <- sum
+				return
+			}
+			sum += items[0]
+		}
+	}()
+	drained := make(chan int)
+	go func() {
+		sum := 0
+		for item := range m.Drain(ctx) {
+			sum += item
+		}
+		drained <- sum
+	}()
+
+	for i := 0; i < 1000; i++ {
+		if i%2 == 0 {
+			a.Enqueue(i)
+		} else {
+			b.Enqueue(i)
+		}
+	}
+	a.Close()
+	b.Close()
+
+	if sum := <-waited + <-drained; sum != 499500 || ctx.Err() != nil {
+		t.Fatalf("Expected every item to be consumed once, got a sum of %d.", sum)
+	}
+}
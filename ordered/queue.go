@@ -0,0 +1,101 @@
+package ordered
+
+import (
+	"cmp"
+	"sync"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/deque"
+)
+
+// Queue is a FIFO queue that knows the minimum and maximum of the items in it.
+//
+// Besides the items, it keeps two monotonic deques of candidates, like window.MinMax:
+// an item stops being a candidate for the minimum as soon as a smaller or equal item
+// is enqueued after it, and vice versa for the maximum. Every item enters and leaves
+// each deque at most once, so enqueueing stays O(1) amortized and Min and Max are O(1).
+type Queue[T cmp.Ordered] struct {
+	mu    sync.Mutex
+	items *cirque.Cirque[T]
+	mins  *deque.Deque[entry[T]] // Candidates for the minimum, increasing from front to back
+	maxs  *deque.Deque[entry[T]] // Candidates for the maximum, decreasing from front to back
+	head  uint64                 // Index of the next item to dequeue
+	tail  uint64                 // Index of the next item to enqueue
+}
+
+type entry[T any] struct {
+	index uint64
+	value T
+}
+
+// NewQueue creates a Queue of initial size n.
+func NewQueue[T cmp.Ordered](n int) *Queue[T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Queue[T]{
+		items: cirque.New[T](n),
+		mins:  deque.New[entry[T]](n),
+		maxs:  deque.New[entry[T]](n),
+	}
+}
+
+// Len returns the number of items in the queue.
+func (q *Queue[T]) Len() int {
+	return q.items.Len()
+}
+
+// Enqueue adds items to the back of the queue.
+func (q *Queue[T]) Enqueue(items ...T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, v := range items {
+		e := entry[T]{index: q.tail, value: v}
+		q.tail++
+		pushCandidate(q.mins, e, func(back T) bool { return back >= v })
+		pushCandidate(q.maxs, e, func(back T) bool { return back <= v })
+	}
+	q.items.Enqueue(items...)
+}
+
+// Append e to a monotonic deque after dropping candidates that e makes obsolete from the back.
+func pushCandidate[T any](d *deque.Deque[entry[T]], e entry[T], obsolete func(T) bool) {
+	for back, ok := d.Back(); ok && obsolete(back.value); back, ok = d.Back() {
+		d.PopBack()
+	}
+	d.PushBack(e)
+}
+
+// Dequeue returns a maximum of n items from the front of the queue.
+func (q *Queue[T]) Dequeue(n int) []T {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := q.items.Dequeue(n)
+	q.head += uint64(len(items))
+
+	// Candidates that were dequeued are no longer in the running.
+	for _, d := range []*deque.Deque[entry[T]]{q.mins, q.maxs} {
+		for front, ok := d.Front(); ok && front.index < q.head; front, ok = d.Front() {
+			d.PopFront()
+		}
+	}
+	return items
+}
+
+// Min returns the smallest item in the queue, or reports false if the queue is empty.
+func (q *Queue[T]) Min() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, ok := q.mins.Front()
+	return e.value, ok
+}
+
+// Max returns the largest item in the queue, or reports false if the queue is empty.
+func (q *Queue[T]) Max() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, ok := q.maxs.Front()
+	return e.value, ok
+}
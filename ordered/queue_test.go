@@ -0,0 +1,39 @@
+package ordered
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestQueueMinMax(t *testing.T) {
+	q := NewQueue[int](4)
+
+	var pending []int
+	for i := 0; i < 500; i++ {
+		if rand.Intn(3) == 0 {
+			n := rand.Intn(3)
+			items := q.Dequeue(n)
+			if !slices.Equal(items, pending[:len(items)]) {
+				t.Fatal("Items missing or reordered.")
+			}
+			pending = pending[len(items):]
+		} else {
+			v := rand.Intn(100)
+			q.Enqueue(v)
+			pending = append(pending, v)
+		}
+
+		min, ok := q.Min()
+		max, _ := q.Max()
+		if len(pending) == 0 {
+			if ok {
+				t.Fatal("Expected an empty queue to have no minimum.")
+			}
+			continue
+		}
+		if min != slices.Min(pending) || max != slices.Max(pending) {
+			t.Fatalf("Expected %d and %d, got %d and %d.", slices.Min(pending), slices.Max(pending), min, max)
+		}
+	}
+}
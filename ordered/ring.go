@@ -0,0 +1,163 @@
+// Package ordered provides a ring that keeps its items sorted.
+package ordered
+
+import (
+	"cmp"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Ring keeps items sorted as they are inserted, smallest first, in a circular buffer.
+//
+// Items are inserted at the position found by binary search, moving the items on the shorter
+// side of it by one, so that inserting near either end is cheap, and popping from either end
+// is O(1). Items that compare equal stay in insertion order. The ring grows as needed.
+type Ring[T any] struct {
+	mu    sync.Mutex
+	slots []T
+	front int // Slot of the smallest item
+	len   int // Number of items in ring
+	cmp   func(a, b T) int
+}
+
+// New creates a Ring of initial size n for ordered items.
+func New[T cmp.Ordered](n int) *Ring[T] {
+	return NewFunc(n, cmp.Compare[T])
+}
+
+// NewFunc creates a Ring of initial size n whose items are ordered by cmp, which returns
+// a negative number when a < b, a positive number when a > b and zero otherwise.
+func NewFunc[T any](n int, cmp func(a, b T) int) *Ring[T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Ring[T]{slots: make([]T, n), cmp: cmp}
+}
+
+// Len returns the number of items in the ring.
+func (r *Ring[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.len
+}
+
+// Slot of the i-th smallest item.
+// Must be called with mu held.
+func (r *Ring[T]) slot(i int) int {
+	return (r.front + i + len(r.slots)) % len(r.slots)
+}
+
+// Double the capacity of the ring, moving items to the start of the new slots.
+// Must be called with mu held.
+func (r *Ring[T]) grow() {
+	slots := make([]T, 2*len(r.slots))
+	for i := 0; i < r.len; i++ {
+		slots[i] = r.slots[r.slot(i)]
+	}
+	r.slots, r.front = slots, 0
+
+	log.Debugf("Grew ordered ring capacity to %d.", len(r.slots))
+}
+
+// Insert adds items to the ring at their sorted positions.
+func (r *Ring[T]) Insert(items ...T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range items {
+		if r.len == len(r.slots) {
+			r.grow()
+		}
+
+		// Insert after the items that compare equal, to keep insertion order among them.
+		i := sort.Search(r.len, func(i int) bool {
+			return r.cmp(r.slots[r.slot(i)], item) > 0
+		})
+
+		if i < r.len/2 {
+			// Move the smaller items one slot towards the front.
+			r.front = r.slot(-1)
+			for k := 0; k < i; k++ {
+				r.slots[r.slot(k)] = r.slots[r.slot(k+1)]
+			}
+		} else {
+			// Move the larger items one slot towards the back.
+			for k := r.len; k > i; k-- {
+				r.slots[r.slot(k)] = r.slots[r.slot(k-1)]
+			}
+		}
+		r.slots[r.slot(i)] = item
+		r.len++
+	}
+}
+
+// Min returns the smallest item, or reports false if the ring is empty.
+func (r *Ring[T]) Min() (T, bool) {
+	return r.At(0)
+}
+
+// Max returns the largest item, or reports false if the ring is empty.
+func (r *Ring[T]) Max() (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.at(r.len - 1)
+}
+
+// At returns the i-th smallest item, or reports false if there are no more than i items.
+func (r *Ring[T]) At(i int) (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.at(i)
+}
+
+// Must be called with mu held.
+func (r *Ring[T]) at(i int) (T, bool) {
+	if i < 0 || i >= r.len {
+		var zero T
+		return zero, false
+	}
+	return r.slots[r.slot(i)], true
+}
+
+// PopMin removes and returns the smallest item, or reports false if the ring is empty.
+func (r *Ring[T]) PopMin() (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.at(0)
+	if ok {
+		var zero T
+		r.slots[r.front] = zero
+		r.front = r.slot(1)
+		r.len--
+	}
+	return item, ok
+}
+
+// PopMax removes and returns the largest item, or reports false if the ring is empty.
+func (r *Ring[T]) PopMax() (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.at(r.len - 1)
+	if ok {
+		var zero T
+		r.slots[r.slot(r.len-1)] = zero
+		r.len--
+	}
+	return item, ok
+}
+
+// Items returns a copy of the items in the ring, smallest first.
+func (r *Ring[T]) Items() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]T, r.len)
+	for i := range result {
+		result[i] = r.slots[r.slot(i)]
+	}
+	return result
+}
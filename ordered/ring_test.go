@@ -0,0 +1,59 @@
+package ordered
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSorted(t *testing.T) {
+	r := New[int](2)
+
+	var expected []int
+	for i := 0; i < 200; i++ {
+		v := rand.Intn(50)
+		r.Insert(v)
+		expected = append(expected, v)
+	}
+	slices.Sort(expected)
+
+	if items := r.Items(); !slices.Equal(items, expected) {
+		t.Fatalf("Expected %v, got %v.", expected, items)
+	}
+}
+
+func TestPop(t *testing.T) {
+	r := New[int](4)
+	r.Insert(5, 1, 4, 2, 3)
+
+	if v, _ := r.PopMin(); v != 1 {
+		t.Fatalf("Expected 1 as minimum, got %d.", v)
+	}
+	if v, _ := r.PopMax(); v != 5 {
+		t.Fatalf("Expected 5 as maximum, got %d.", v)
+	}
+	r.Insert(0, 6)
+	if items := r.Items(); !slices.Equal(items, []int{0, 2, 3, 4, 6}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+
+	for r.Len() > 0 {
+		r.PopMax()
+	}
+	if _, ok := r.Min(); ok {
+		t.Fatal("Expected an empty ring to have no minimum.")
+	}
+	if _, ok := r.PopMin(); ok {
+		t.Fatal("Expected nothing to pop from an empty ring.")
+	}
+}
+
+func TestEqualItemsKeepOrder(t *testing.T) {
+	r := NewFunc(4, func(a, b string) int { return strings.Compare(a[:1], b[:1]) })
+	r.Insert("b1", "a1", "b2", "a2", "b3")
+
+	if items := r.Items(); !slices.Equal(items, []string{"a1", "a2", "b1", "b2", "b3"}) {
+		t.Fatalf("Expected equal items in insertion order, got %v.", items)
+	}
+}
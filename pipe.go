@@ -0,0 +1,66 @@
+package cirque
+
+import (
+	"errors"
+	"io"
+
+	"github.com/denis-ismailaj/cirque/bytesring"
+)
+
+// Pipe creates an in-memory pipe like io.Pipe, but with a ring buffer of size bytes in the middle.
+// Writes only block while the buffer is full, so small writes don't have to wait for a matching read.
+//
+// Closing the writer lets the reader drain the buffer before getting io.EOF.
+// Closing the reader discards buffered data and makes further writes fail with io.ErrClosedPipe.
+// Both ends are safe to use from multiple goroutines.
+func Pipe(size int) (io.ReadCloser, io.WriteCloser) {
+	r := bytesring.New(size)
+	if r == nil {
+		return nil, nil
+	}
+	return pipeReader{r}, pipeWriter{r}
+}
+
+type pipeReader struct {
+	r *bytesring.Ring
+}
+
+func (p pipeReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	return n, pipeError(err)
+}
+
+func (p pipeReader) WriteTo(w io.Writer) (int64, error) {
+	n, err := p.r.WriteTo(w)
+	return n, pipeError(err)
+}
+
+func (p pipeReader) Close() error {
+	return p.r.CloseRead()
+}
+
+type pipeWriter struct {
+	r *bytesring.Ring
+}
+
+func (p pipeWriter) Write(b []byte) (int, error) {
+	n, err := p.r.Write(b)
+	return n, pipeError(err)
+}
+
+func (p pipeWriter) ReadFrom(r io.Reader) (int64, error) {
+	n, err := p.r.ReadFrom(r)
+	return n, pipeError(err)
+}
+
+func (p pipeWriter) Close() error {
+	return p.r.Close()
+}
+
+// Report use of a closed ring the same way io.Pipe does.
+func pipeError(err error) error {
+	if errors.Is(err, bytesring.ErrClosed) {
+		return io.ErrClosedPipe
+	}
+	return err
+}
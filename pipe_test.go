@@ -0,0 +1,44 @@
+package cirque
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	r, w := Pipe(8)
+
+	// Small writes must not wait for a reader.
+	for i := 0; i < 4; i++ {
+		if _, err := w.Write([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil || !bytes.Equal(b, []byte{0, 1, 2, 3}) {
+		t.Fatalf("Unexpected read %v, %v.", b, err)
+	}
+}
+
+func TestPipeReaderClosed(t *testing.T) {
+	r, w := Pipe(1)
+
+	w.Write([]byte{0})
+	done := make(chan error)
+	go func() {
+		// This write blocks until the reader goes away.
+		_, err := w.Write([]byte{1})
+		done <- err
+	}()
+
+	r.Close()
+	if err := <-done; err != io.ErrClosedPipe {
+		t.Fatalf("Expected io.ErrClosedPipe, got %v.", err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Fatalf("Expected io.ErrClosedPipe, got %v.", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package priority provides a queue with a fixed number of priority levels, each backed by its own Cirque.
+package priority
+
+import (
+	"github.com/denis-ismailaj/cirque"
+	log "github.com/sirupsen/logrus"
+)
+
+// Queue is a FIFO queue per priority level, where items of higher priority are always
+// dequeued first. Level 0 is the highest priority. Every level grows independently.
+type Queue[T any] struct {
+	levels []*cirque.Cirque[T]
+}
+
+// New creates a Queue with the given number of priority levels, each of initial size n.
+func New[T any](levels, n int) *Queue[T] {
+	if levels <= 0 || n <= 0 {
+		return nil
+	}
+	q := &Queue[T]{levels: make([]*cirque.Cirque[T], levels)}
+	for i := range q.levels {
+		q.levels[i] = cirque.New[T](n)
+	}
+	return q
+}
+
+// Levels returns the number of priority levels.
+func (q *Queue[T]) Levels() int {
+	return len(q.levels)
+}
+
+// Len returns the number of items currently in the queue across all levels.
+func (q *Queue[T]) Len() int {
+	n := 0
+	for _, l := range q.levels {
+		n += l.Len()
+	}
+	return n
+}
+
+// Enqueue adds items with the given priority, which is clamped to the available levels.
+func (q *Queue[T]) Enqueue(priority int, items ...T) {
+	if priority < 0 || priority >= len(q.levels) {
+		log.Warningf("Priority %d out of range, clamping.", priority)
+		priority = max(0, min(priority, len(q.levels)-1))
+	}
+	q.levels[priority].Enqueue(items...)
+}
+
+// Dequeue returns a maximum of n items, taking them from the highest priority levels first.
+// Items of the same priority keep their FIFO order.
+func (q *Queue[T]) Dequeue(n int) []T {
+	var result []T
+	for _, l := range q.levels {
+		if len(result) >= n {
+			break
+		}
+		result = append(result, l.Dequeue(n-len(result))...)
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+package priority
+
+import "testing"
+
+func TestPriority(t *testing.T) {
+	q := New[string](3, 2)
+
+	q.Enqueue(2, "bulk1", "bulk2", "bulk3")
+	q.Enqueue(0, "urgent1")
+	q.Enqueue(1, "normal1")
+	q.Enqueue(0, "urgent2")
+
+	if q.Len() != 6 {
+		t.Fatalf("Expected 6 items, got %d.", q.Len())
+	}
+
+	expected := []string{"urgent1", "urgent2", "normal1", "bulk1", "bulk2", "bulk3"}
+	items := append(q.Dequeue(3), q.Dequeue(10)...)
+	for i := range expected {
+		if items[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v.", expected, items)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package rate provides an event-rate calculator over a ring of timestamps.
+package rate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// Counter records event timestamps in a ring and counts the events within a sliding window.
+// Expired timestamps are dropped from the front of the ring as time passes, so every operation
+// is O(1) amortized, apart from CountIn which does a binary search.
+//
+// The ring holds at most n timestamps. If more than n events happen within the window the
+// oldest ones are overwritten, and counts saturate at n.
+type Counter struct {
+	mu     sync.Mutex
+	window time.Duration
+	times  []time.Time
+	start  int // Position of the oldest timestamp
+	len    int
+	clock  clock.Clock
+}
+
+// Option configures a Counter.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the counter tell the current time with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// NewCounter creates a Counter over the given window that tracks up to n events.
+func NewCounter(window time.Duration, n int, opts ...Option) *Counter {
+	if window <= 0 || n <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Counter{window: window, times: make([]time.Time, n), clock: cfg.clock}
+}
+
+// Now returns the current time on the clock of the counter.
+func (c *Counter) Now() time.Time {
+	return c.clock.Now()
+}
+
+// Window returns the duration of the sliding window.
+func (c *Counter) Window() time.Duration {
+	return c.window
+}
+
+// Record records an event happening now.
+func (c *Counter) Record() {
+	c.RecordAt(c.clock.Now())
+}
+
+// RecordAt records an event happening at t. Events are expected in time order.
+func (c *Counter) RecordAt(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(t)
+	c.push(t)
+}
+
+// Must be called with mu held.
+func (c *Counter) push(t time.Time) {
+	if c.len == len(c.times) {
+		c.start = (c.start + 1) % len(c.times)
+		c.len--
+	}
+	c.times[(c.start+c.len)%len(c.times)] = t
+	c.len++
+}
+
+// Drop timestamps that have left the window at now. Must be called with mu held.
+func (c *Counter) expire(now time.Time) {
+	cutoff := now.Add(-c.window)
+	for c.len > 0 && !c.times[c.start].After(cutoff) {
+		c.start = (c.start + 1) % len(c.times)
+		c.len--
+	}
+}
+
+// Must be called with mu held.
+func (c *Counter) at(i int) time.Time {
+	return c.times[(c.start+i)%len(c.times)]
+}
+
+// Count returns the number of events within the window ending now.
+func (c *Counter) Count() int {
+	return c.CountAt(c.clock.Now())
+}
+
+// CountAt returns the number of events within the window ending at now.
+func (c *Counter) CountAt(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(now)
+	return c.len
+}
+
+// CountIn returns the number of events within the last d, which is capped to the window.
+func (c *Counter) CountIn(d time.Duration) int {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(now)
+	cutoff := now.Add(-min(d, c.window))
+	// Timestamps are sorted, so find the first one inside the last d.
+	return c.len - sort.Search(c.len, func(i int) bool { return c.at(i).After(cutoff) })
+}
+
+// Rate returns the average number of events per second within the window ending now.
+func (c *Counter) Rate() float64 {
+	return float64(c.Count()) / c.window.Seconds()
+}
+
+// Oldest returns the timestamp of the oldest event within the window ending at now,
+// or reports false if there is none. The count drops when that event leaves the window.
+func (c *Counter) Oldest(now time.Time) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expire(now)
+	if c.len == 0 {
+		return time.Time{}, false
+	}
+	return c.at(0), true
+}
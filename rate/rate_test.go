@@ -0,0 +1,67 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestCounter(t *testing.T) {
+	c := NewCounter(10*time.Second, 100)
+	base := time.Unix(1000, 0)
+
+	for i := 0; i < 20; i++ {
+		c.RecordAt(base.Add(time.Duration(i) * time.Second))
+	}
+
+	// Events at 10..19 are within the window ending at 19, the one at 9 just left it.
+	if n := c.CountAt(base.Add(19 * time.Second)); n != 10 {
+		t.Fatalf("Expected 10 events, got %d.", n)
+	}
+	if oldest, _ := c.Oldest(base.Add(19 * time.Second)); !oldest.Equal(base.Add(10 * time.Second)) {
+		t.Fatalf("Unexpected oldest event %v.", oldest)
+	}
+	if n := c.CountAt(base.Add(time.Hour)); n != 0 {
+		t.Fatalf("Expected every event to expire, got %d.", n)
+	}
+}
+
+func TestCountIn(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	c := NewCounter(time.Minute, 100, WithClock(clk))
+
+	// One event every 5 seconds, up to now.
+	for i := 0; i < 10; i++ {
+		if i > 0 {
+			clk.Advance(5 * time.Second)
+		}
+		c.Record()
+	}
+
+	if n := c.CountIn(12 * time.Second); n != 3 {
+		t.Fatalf("Expected 3 events in the last 12s, got %d.", n)
+	}
+	if n := c.CountIn(time.Hour); n != 10 {
+		t.Fatalf("Expected 10 events in the window, got %d.", n)
+	}
+	if r := c.Rate(); r != 10.0/60 {
+		t.Fatalf("Unexpected rate %v.", r)
+	}
+
+	// The first event leaves the window a minute after it was recorded.
+	clk.Advance(15 * time.Second)
+	if n := c.Count(); n != 9 {
+		t.Fatalf("Expected 9 events once the first one left the window, got %d.", n)
+	}
+}
+
+func TestSaturation(t *testing.T) {
+	c := NewCounter(time.Minute, 3)
+	for i := 0; i < 5; i++ {
+		c.Record()
+	}
+	if c.Count() != 3 {
+		t.Fatalf("Expected the count to saturate at 3, got %d.", c.Count())
+	}
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+)
+
+// Consumer dequeues from a Cirque at a limited rate, for feeding rate-limited downstream
+// APIs straight from a queue.
+type Consumer[T any] struct {
+	queue   *cirque.Cirque[T]
+	limiter *Limiter
+}
+
+// NewConsumer creates a Consumer of q that takes at most perSecond items per second on average,
+// and up to burst items at once after being idle.
+func NewConsumer[T any](q *cirque.Cirque[T], perSecond float64, burst int, opts ...Option) *Consumer[T] {
+	if perSecond <= 0 || burst <= 0 {
+		return nil
+	}
+	// A sliding window of burst events spans as long as it takes to consume them at the rate.
+	window := time.Duration(float64(burst) / perSecond * float64(time.Second))
+	return &Consumer[T]{queue: q, limiter: New(burst, window, opts...)}
+}
+
+// Dequeue returns a maximum of n items, waiting for the rate to allow at least one of them.
+// It doesn't wait for items, so it returns nothing while the queue is empty.
+func (c *Consumer[T]) Dequeue(ctx context.Context, n int) ([]T, error) {
+	n = min(n, c.queue.Len())
+	if n <= 0 {
+		return nil, nil
+	}
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	allowed := 1
+	for allowed < n && c.limiter.Allow() {
+		allowed++
+	}
+	return c.queue.Dequeue(allowed), nil
+}
+
+// Drain returns an iterator that dequeues items one by one as they arrive, waiting for the
+// rate to allow each of them. The iteration ends when the queue is closed and empty, or when
+// ctx is done.
+func (c *Consumer[T]) Drain(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		next, stop := iter.Pull(c.queue.Drain(ctx))
+		defer stop()
+
+		for {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return
+			}
+			item, ok := next()
+			if !ok || !yield(item) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+)
+
+func TestConsumerDequeue(t *testing.T) {
+	q := cirque.New[int](8)
+	q.Enqueue(0, 1, 2, 3, 4, 5)
+	c := NewConsumer(q, 100, 4)
+
+	// A burst is allowed right away.
+	items, err := c.Dequeue(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("Expected a burst of 4 items, got %d.", len(items))
+	}
+
+	// After that the consumer has to wait for the rate.
+	start := time.Now()
+	items, err = c.Dequeue(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 || time.Since(start) < 5*time.Millisecond {
+		t.Fatal("Expected the consumer to wait for the rate.")
+	}
+}
+
+func TestConsumerDrain(t *testing.T) {
+	q := cirque.New[int](8)
+	q.Enqueue(0, 1, 2, 3, 4)
+	q.Close()
+	c := NewConsumer(q, 200, 1)
+
+	start := time.Now()
+	n := 0
+	for range c.Drain(context.Background()) {
+		n++
+	}
+	if n != 5 {
+		t.Fatalf("Expected 5 items, got %d.", n)
+	}
+	// The first item is free, every other one waits 5ms.
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("Expected items to be consumed at the rate.")
+	}
+}
@@ -0,0 +1,95 @@
+// Package ratelimit provides a sliding-window rate limiter backed by a ring of timestamps.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/rate"
+)
+
+// Limiter allows at most limit events within any window of the given duration.
+//
+// It keeps the timestamps of the allowed events in a rate.Counter, a sliding-window log,
+// so unlike fixed-window limiters it never lets through bursts of twice the limit around
+// window boundaries.
+type Limiter struct {
+	mu      sync.Mutex
+	limit   int
+	counter *rate.Counter
+	clock   clock.Clock
+}
+
+// Option configures a Limiter or a Consumer.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the limiter tell time and wait with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// New creates a Limiter that allows limit events per window.
+func New(limit int, window time.Duration, opts ...Option) *Limiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Limiter{limit: limit, counter: rate.NewCounter(window, limit, rate.WithClock(cfg.clock)), clock: cfg.clock}
+}
+
+// Allow reports whether an event may happen now, and records it if so.
+func (l *Limiter) Allow() bool {
+	ok, _ := l.reserve(l.clock.Now())
+	return ok
+}
+
+// Record the event at now if there is room for it. Otherwise return how long to wait
+// for the oldest event to leave the window.
+func (l *Limiter) reserve(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.counter.CountAt(now) < l.limit {
+		l.counter.RecordAt(now)
+		return true, 0
+	}
+	oldest, _ := l.counter.Oldest(now)
+	return false, oldest.Add(l.counter.Window()).Sub(now)
+}
+
+// Wait blocks until an event may happen and records it, or returns the context's error
+// if ctx is done first.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		ok, wait := l.reserve(l.clock.Now())
+		if ok {
+			return nil
+		}
+
+		due, timer := clock.After(l.clock, wait)
+		select {
+		case <-due:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Remaining returns the number of events that may still happen in the window ending now.
+func (l *Limiter) Remaining() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit - l.counter.Count()
+}
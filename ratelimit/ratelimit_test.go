@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestAllow(t *testing.T) {
+	l := New(3, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow() {
+			t.Fatalf("Expected event %d to be allowed.", i)
+		}
+	}
+	if l.Allow() {
+		t.Fatal("Expected the limit to be enforced.")
+	}
+	if l.Remaining() != 0 {
+		t.Fatalf("Expected nothing remaining, got %d.", l.Remaining())
+	}
+}
+
+func TestWait(t *testing.T) {
+	window := 20 * time.Millisecond
+	l := New(2, window)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The second pair has to wait for the first one to leave the window.
+	if elapsed := time.Since(start); elapsed < window {
+		t.Fatalf("Expected to wait at least %v, waited %v.", window, elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v.", err)
+	}
+}
+
+func TestWaitClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	l := New(1, time.Hour, WithClock(c))
+	l.Allow()
+
+	done := make(chan error)
+	go func() {
+		done <- l.Wait(context.Background())
+	}()
+
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(time.Hour)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if l.Remaining() != 0 {
+		t.Fatal("Expected the wait to record an event.")
+	}
+}
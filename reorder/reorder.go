@@ -0,0 +1,164 @@
+// Package reorder provides a buffer that releases sequence-numbered items strictly in order.
+package reorder
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrDuplicate is returned when pushing a sequence number that is already buffered.
+	ErrDuplicate = errors.New("reorder: duplicate sequence number")
+	// ErrTooOld is returned when pushing a sequence number that was already released or skipped.
+	ErrTooOld = errors.New("reorder: sequence number already passed")
+	// ErrTooFar is returned when pushing a sequence number beyond the end of the window.
+	ErrTooFar = errors.New("reorder: sequence number beyond window")
+)
+
+// Buffer accepts items with sequence numbers in any order and releases them in sequence order.
+//
+// Items are kept in a ring of window slots, where the item with sequence number s lives in
+// slot s % window. If the next expected item doesn't arrive within the gap timeout while later
+// items are waiting, the gap is skipped and the missing sequence numbers are counted as lost.
+type Buffer[T any] struct {
+	mu       sync.Mutex
+	slots    []slot[T]
+	next     uint64        // Sequence number of the next item to release
+	pending  int           // Number of buffered items
+	timeout  time.Duration // How long to wait for a missing item before skipping it
+	gapSince time.Time     // When the current gap started blocking buffered items
+	lost     uint64        // Number of sequence numbers skipped so far
+	clock    clock.Clock
+}
+
+// Option configures a Buffer.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes the buffer time gaps with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+type slot[T any] struct {
+	item T
+	ok   bool
+}
+
+// New creates a Buffer that expects start as the first sequence number, accepts items up to
+// window sequence numbers ahead, and skips gaps that block buffered items for longer than timeout.
+// A timeout of zero never skips gaps.
+func New[T any](start uint64, window int, timeout time.Duration, opts ...Option) *Buffer[T] {
+	if window <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Buffer[T]{
+		slots:   make([]slot[T], window),
+		next:    start,
+		timeout: timeout,
+		clock:   cfg.clock,
+	}
+}
+
+// Push adds item with sequence number seq.
+func (b *Buffer[T]) Push(seq uint64, item T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case seq < b.next:
+		return ErrTooOld
+	case seq-b.next >= uint64(len(b.slots)):
+		return ErrTooFar
+	}
+
+	s := &b.slots[seq%uint64(len(b.slots))]
+	if s.ok {
+		return ErrDuplicate
+	}
+	s.item, s.ok = item, true
+	b.pending++
+
+	if seq != b.next && b.gapSince.IsZero() {
+		b.gapSince = b.clock.Now()
+	}
+	return nil
+}
+
+// Pop returns the items that can be released in order. If the next expected item has been
+// missing for longer than the gap timeout, the gap is skipped first.
+func (b *Buffer[T]) Pop() []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending > 0 && !b.slot(b.next).ok && b.timeout > 0 && clock.Since(b.clock, b.gapSince) >= b.timeout {
+		b.skip()
+	}
+
+	var result []T
+	for s := b.slot(b.next); s.ok; s = b.slot(b.next) {
+		result = append(result, s.item)
+		*s = slot[T]{}
+		b.pending--
+		b.next++
+	}
+
+	// Any remaining items are blocked by a new gap.
+	if len(result) > 0 {
+		b.gapSince = time.Time{}
+		if b.pending > 0 {
+			b.gapSince = b.clock.Now()
+		}
+	}
+	return result
+}
+
+// Skip the missing sequence numbers up to the first buffered item.
+// Must be called with mu held and with at least one item pending.
+func (b *Buffer[T]) skip() {
+	start := b.next
+	for !b.slot(b.next).ok {
+		b.next++
+	}
+	b.lost += b.next - start
+	log.Debugf("Skipped %d missing sequence numbers starting at %d.", b.next-start, start)
+}
+
+// Must be called with mu held.
+func (b *Buffer[T]) slot(seq uint64) *slot[T] {
+	return &b.slots[seq%uint64(len(b.slots))]
+}
+
+// Next returns the sequence number of the next item to be released.
+func (b *Buffer[T]) Next() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.next
+}
+
+// Len returns the number of buffered items waiting to be released.
+func (b *Buffer[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending
+}
+
+// Lost returns the number of sequence numbers that were skipped because they never arrived.
+func (b *Buffer[T]) Lost() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lost
+}
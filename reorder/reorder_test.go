@@ -0,0 +1,60 @@
+package reorder
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestReorder(t *testing.T) {
+	b := New[string](10, 4, 0)
+
+	if err := b.Push(12, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Push(11, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if items := b.Pop(); len(items) != 0 {
+		t.Fatalf("Expected nothing before the first item arrives, got %v.", items)
+	}
+	if err := b.Push(12, "c"); err != ErrDuplicate {
+		t.Fatal("Expected ErrDuplicate.")
+	}
+	if err := b.Push(14, "e"); err != ErrTooFar {
+		t.Fatal("Expected ErrTooFar.")
+	}
+
+	b.Push(10, "a")
+	if items := b.Pop(); !slices.Equal(items, []string{"a", "b", "c"}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if err := b.Push(11, "b"); err != ErrTooOld {
+		t.Fatal("Expected ErrTooOld.")
+	}
+	if b.Next() != 13 || b.Len() != 0 {
+		t.Fatal("Unexpected state after releasing items.")
+	}
+}
+
+func TestGapTimeout(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	b := New[int](0, 8, 10*time.Second, WithClock(c))
+
+	b.Push(2, 2)
+	b.Push(3, 3)
+	c.Advance(9 * time.Second)
+	if items := b.Pop(); len(items) != 0 {
+		t.Fatal("Expected the gap to block items.")
+	}
+
+	c.Advance(time.Second)
+	if items := b.Pop(); !slices.Equal(items, []int{2, 3}) {
+		t.Fatalf("Expected the gap to be skipped, got %v.", items)
+	}
+	if b.Lost() != 2 {
+		t.Fatalf("Expected 2 lost items, got %d.", b.Lost())
+	}
+}
@@ -0,0 +1,192 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrUnknownToken is returned when committing or rolling back a reservation that was already
+// committed or rolled back.
+var ErrUnknownToken = errors.New("cirque: unknown reservation token")
+
+// Token identifies a reservation made with Reserve.
+type Token uint64
+
+// Reserve removes a maximum of n items from the queue like Dequeue, but they can be put back
+// at the front of the queue with Rollback until the reservation is finalized with Commit.
+// This allows a transactional handoff, where items are only gone once they are stored elsewhere.
+// If the queue is empty, nothing is reserved and the zero token is returned, which Commit and
+// Rollback accept as a no-op.
+func (cq *Cirque[T]) Reserve(n int) ([]T, Token) {
+	// Keep the items as they are stored, so that they get their expiry and enqueue time back on Rollback.
+	var items []T
+	var values []any
+	cq.take(n, func(_ uint64, item T, v any) {
+		items = append(items, item)
+		values = append(values, v)
+	})
+	log.Debugf("Reserving %d items.", len(items))
+	if len(items) == 0 {
+		return items, 0
+	}
+
+	cq.reserveMu.Lock()
+	defer cq.reserveMu.Unlock()
+
+	if cq.reservations == nil {
+		cq.reservations = make(map[Token][]any)
+	}
+	cq.lastToken++
+	cq.reservations[cq.lastToken] = values
+
+	return items, cq.lastToken
+}
+
+// Commit finalizes a reservation, so its items can no longer be rolled back.
+func (cq *Cirque[T]) Commit(token Token) error {
+	if token == 0 {
+		return nil
+	}
+	_, err := cq.release(token)
+	if err == nil {
+		cq.finalize()
+	}
+	return err
+}
+
+// Rollback puts the items of a reservation back at the front of the queue, in their original order.
+// Rolled back items take back their sequence numbers, which is only accurate if nothing else
+// was dequeued since they were reserved, along with their expiry and enqueue time.
+func (cq *Cirque[T]) Rollback(token Token) error {
+	if token == 0 {
+		return nil
+	}
+	values, err := cq.release(token)
+	if err != nil {
+		return err
+	}
+
+	cq.putFront(values)
+	cq.finalize()
+	log.Debugf("Rolled back %d items.", len(values))
+	return nil
+}
+
+// Wake up goroutines waiting for reservations to be finalized.
+func (cq *Cirque[T]) finalize() {
+	cq.reserveMu.Lock()
+	defer cq.reserveMu.Unlock()
+
+	if cq.finalized != nil {
+		close(cq.finalized)
+		cq.finalized = nil
+	}
+}
+
+// Wait until every reservation is finalized, or ctx is done.
+func (cq *Cirque[T]) awaitReservations(ctx context.Context) error {
+	for {
+		cq.reserveMu.Lock()
+		if len(cq.reservations) == 0 {
+			cq.reserveMu.Unlock()
+			return nil
+		}
+		if cq.finalized == nil {
+			cq.finalized = make(chan struct{})
+		}
+		finalized := cq.finalized
+		log.Debugf("Waiting for %d reservations.", len(cq.reservations))
+		cq.reserveMu.Unlock()
+
+		select {
+		case <-finalized:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Remove a reservation and return its items, as stored in the ring.
+func (cq *Cirque[T]) release(token Token) ([]any, error) {
+	cq.reserveMu.Lock()
+	defer cq.reserveMu.Unlock()
+
+	values, ok := cq.reservations[token]
+	if !ok {
+		return nil, ErrUnknownToken
+	}
+	delete(cq.reservations, token)
+	return values, nil
+}
+
+// Put items, as stored in the ring, back in front of the reader head, in order.
+func (cq *Cirque[T]) putFront(values []any) {
+	if len(values) == 0 {
+		return
+	}
+
+	// The free slots are the ones between the writer head and the reader head,
+	// so keep writers away while the reader head moves back into them.
+	cq.writeMu.Lock()
+	defer cq.writeMu.Unlock()
+
+	// One slot always stays empty, as in Enqueue.
+	if free := cq.cap - 1 - cq.Len(); free < len(values) {
+		cq.grow(cq.cap + len(values))
+	}
+
+	cq.readMu.Lock()
+	defer cq.readMu.Unlock()
+
+	// Fill the free slots right before the reader head, last item first.
+	h := cq.getReaderHead()
+	for i := len(values) - 1; i >= 0; i-- {
+		h = h.Prev()
+		h.Value = values[i]
+	}
+
+	cq.setReaderHead(h)
+	cq.len.Add(int64(len(values)))
+	cq.readSeq -= uint64(len(values))
+
+	// Items that were already in the queue take their share of the bound back,
+	// even if that puts it over the limit for a while.
+	if cq.bound.limit > 0 {
+		var cost int64
+		for _, v := range values {
+			item, _ := unbox[T](v)
+			cost += cq.bound.cost(item)
+		}
+		cq.bound.mu.Lock()
+		cq.bound.used += cost
+		cq.bound.mu.Unlock()
+	}
+
+	cq.signal()
+}
+
+// Claim hands out the item at the front of the queue exclusively to the caller, who must call
+// release once done with it: with requeue set to put it back at the front of the queue, or unset
+// to remove it for good. It returns false if the queue is empty.
+// This is a lighter alternative to the ack package for workers within a single process.
+func (cq *Cirque[T]) Claim() (item T, release func(requeue bool), ok bool) {
+	items, token := cq.Reserve(1)
+	if len(items) == 0 {
+		return item, nil, false
+	}
+
+	var once sync.Once
+	release = func(requeue bool) {
+		once.Do(func() {
+			if requeue {
+				_ = cq.Rollback(token)
+			} else {
+				_ = cq.Commit(token)
+			}
+		})
+	}
+	return items[0], release, true
+}
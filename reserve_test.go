@@ -0,0 +1,138 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestReserveCommit(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2, 3)
+
+	items, token := cq.Reserve(2)
+	if !slices.Equal(items, []int{1, 2}) || cq.Len() != 1 {
+		t.Fatalf("Unexpected reservation %v.", items)
+	}
+	if err := cq.Commit(token); err != nil {
+		t.Fatal(err)
+	}
+	if err := cq.Rollback(token); err != ErrUnknownToken {
+		t.Fatal("Expected rollback after commit to fail.")
+	}
+	if items := cq.Dequeue(5); !slices.Equal(items, []int{3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestReserveRollback(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2, 3)
+
+	_, token := cq.Reserve(2)
+	cq.Enqueue(4, 5)
+
+	// The queue has to grow to take the items back.
+	if err := cq.Rollback(token); err != nil {
+		t.Fatal(err)
+	}
+	cq.Enqueue(6)
+
+	items := cq.DequeueSeq(10)
+	for i, item := range items {
+		if item.Item != i+1 || item.Seq != uint64(i+1) {
+			t.Fatal("Items missing or reordered.")
+		}
+	}
+	if len(items) != 6 {
+		t.Fatalf("Expected 6 items, got %d.", len(items))
+	}
+}
+
+func TestReserveEmpty(t *testing.T) {
+	cq := New[int](4)
+
+	items, token := cq.Reserve(1)
+	if len(items) != 0 || token != 0 {
+		t.Fatalf("Expected nothing to be reserved, got %v.", items)
+	}
+	if err := cq.Commit(token); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reservation of nothing doesn't hold up closing the queue.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := cq.CloseAndDrain(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRollbackBounded(t *testing.T) {
+	cq := New(4, WithBound[int](2))
+	cq.Enqueue(1, 2)
+
+	_, token := cq.Reserve(2)
+	if err := cq.TryEnqueue(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := cq.Rollback(token); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rolled back items count against the bound again.
+	if err := cq.TryEnqueue(4); !errors.Is(err, ErrFull) {
+		t.Fatal("Expected the queue to be full.")
+	}
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{1, 2, 3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	cq := New[int](4)
+	cq.Enqueue(1, 2)
+
+	item, release, ok := cq.Claim()
+	if !ok || item != 1 {
+		t.Fatalf("Unexpected claim %d.", item)
+	}
+	release(true)
+	release(false)
+
+	// A requeued item is handed out again first.
+	item, release, _ = cq.Claim()
+	if item != 1 {
+		t.Fatalf("Expected the requeued item, got %d.", item)
+	}
+	release(false)
+
+	cq.Dequeue(1)
+	if _, _, ok := cq.Claim(); ok {
+		t.Fatal("Expected no claim from an empty queue.")
+	}
+}
+
+func TestRollbackKeepsTTL(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	cq := New(4, WithClock[int](c), WithEnqueueTime[int]())
+	cq.EnqueueWithTTL(1, time.Second)
+
+	_, token := cq.Reserve(1)
+	c.Advance(500 * time.Millisecond)
+	if err := cq.Rollback(token); err != nil {
+		t.Fatal(err)
+	}
+	if age, ok := cq.OldestAge(); !ok || age != 500*time.Millisecond {
+		t.Fatalf("Expected the rolled back item to keep its enqueue time, got %v.", age)
+	}
+
+	c.Advance(time.Second)
+	if items := cq.Dequeue(1); len(items) != 0 {
+		t.Fatalf("Expected the rolled back item to expire, got %v.", items)
+	}
+}
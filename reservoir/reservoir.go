@@ -0,0 +1,69 @@
+// Package reservoir provides a fixed-size uniform sample of an unbounded stream.
+package reservoir
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// Sampler keeps a statistically uniform sample of k items out of everything pushed to it,
+// using reservoir sampling (Algorithm R). Every push is O(1).
+type Sampler[T any] struct {
+	mu    sync.Mutex
+	items []T
+	seen  uint64 // Number of items pushed so far
+	rng   *rand.Rand
+}
+
+// New creates a Sampler that keeps k items.
+func New[T any](k int) *Sampler[T] {
+	return NewWithRand[T](k, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+}
+
+// NewWithRand creates a Sampler that keeps k items and draws random numbers from rng,
+// so that sampling can be made reproducible.
+func NewWithRand[T any](k int, rng *rand.Rand) *Sampler[T] {
+	if k <= 0 {
+		return nil
+	}
+	return &Sampler[T]{items: make([]T, 0, k), rng: rng}
+}
+
+// Push offers item to the sample. The i-th item pushed ends up in the sample with probability k/i.
+func (s *Sampler[T]) Push(item T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seen++
+	if len(s.items) < cap(s.items) {
+		s.items = append(s.items, item)
+		return
+	}
+	if j := s.rng.Uint64N(s.seen); j < uint64(len(s.items)) {
+		s.items[j] = item
+	}
+}
+
+// Sample returns a copy of the current sample, in no particular order.
+func (s *Sampler[T]) Sample() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]T(nil), s.items...)
+}
+
+// Seen returns the number of items pushed so far.
+func (s *Sampler[T]) Seen() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seen
+}
+
+// Reset empties the sample and the count of items seen.
+func (s *Sampler[T]) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.items)
+	s.items = s.items[:0]
+	s.seen = 0
+}
@@ -0,0 +1,44 @@
+package reservoir
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSampler(t *testing.T) {
+	s := NewWithRand[int](3, rand.New(rand.NewPCG(1, 2)))
+
+	s.Push(1)
+	s.Push(2)
+	if len(s.Sample()) != 2 {
+		t.Fatal("Expected every item to be kept before the reservoir is full.")
+	}
+
+	s.Reset()
+	if len(s.Sample()) != 0 || s.Seen() != 0 {
+		t.Fatal("Expected Reset to empty the sampler.")
+	}
+}
+
+func TestUniform(t *testing.T) {
+	k, n, rounds := 10, 100, 2000
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	counts := make([]int, n)
+	for r := 0; r < rounds; r++ {
+		s := NewWithRand[int](k, rng)
+		for i := 0; i < n; i++ {
+			s.Push(i)
+		}
+		for _, item := range s.Sample() {
+			counts[item]++
+		}
+	}
+
+	// Every item should be sampled about rounds*k/n = 200 times.
+	for i, c := range counts {
+		if c < 120 || c > 280 {
+			t.Fatalf("Item %d sampled %d times, expected about 200.", i, c)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Result is an item of a pipeline stage that either carries a value or the error that kept
+// the stage from producing one, so that failures travel downstream in the same queue.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Ok returns a successful Result carrying v.
+func Ok[T any](v T) Result[T] {
+	return Result[T]{Value: v}
+}
+
+// Failed returns a Result carrying err.
+func Failed[T any](err error) Result[T] {
+	return Result[T]{Err: err}
+}
+
+// Unwrap returns the value and the error of the result.
+func (r Result[T]) Unwrap() (T, error) {
+	return r.Value, r.Err
+}
+
+// EnqueueResult adds the outcome of a stage to q, as a failure if err isn't nil and as v otherwise.
+func EnqueueResult[T any](q *Cirque[Result[T]], v T, err error) {
+	if err != nil {
+		q.Enqueue(Failed[T](err))
+		return
+	}
+	q.Enqueue(Ok(v))
+}
+
+// Split moves results from q into ok and failed as they arrive, values into the former and errors
+// into the latter. Once q is closed and drained, it closes ok and failed and returns nil,
+// so that consumers downstream finish too. If ctx is done first, it returns the context's error.
+func Split[T any](ctx context.Context, q *Cirque[Result[T]], ok *Cirque[T], failed *Cirque[error]) error {
+	for {
+		results, err := q.DequeueWait(ctx, 64)
+		if errors.Is(err, ErrClosed) {
+			ok.Close()
+			failed.Close()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		values := make([]T, 0, len(results))
+		var errs []error
+		for _, r := range results {
+			if r.Err != nil {
+				errs = append(errs, r.Err)
+			} else {
+				values = append(values, r.Value)
+			}
+		}
+		ok.Enqueue(values...)
+		failed.Enqueue(errs...)
+
+		log.Debugf("Split %d results into %d values and %d errors.", len(results), len(values), len(errs))
+	}
+}
@@ -0,0 +1,41 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	q := New[Result[int]](4)
+	boom := errors.New("boom")
+	EnqueueResult(q, 1, nil)
+	EnqueueResult(q, 0, boom)
+	EnqueueResult(q, 2, nil)
+	q.Close()
+
+	ok, failed := New[int](4), New[error](4)
+	if err := Split(context.Background(), q, ok, failed); err != nil {
+		t.Fatal(err)
+	}
+
+	if values := ok.Dequeue(10); !slices.Equal(values, []int{1, 2}) {
+		t.Fatalf("Expected values 1 and 2, got %v.", values)
+	}
+	if errs := failed.Dequeue(10); len(errs) != 1 || errs[0] != boom {
+		t.Fatalf("Expected the failure, got %v.", errs)
+	}
+	if _, err := ok.DequeueWait(context.Background(), 1); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected the outputs to be closed.")
+	}
+}
+
+func TestResultUnwrap(t *testing.T) {
+	if v, err := Ok(1).Unwrap(); v != 1 || err != nil {
+		t.Fatal("Expected a successful result.")
+	}
+	if _, err := Failed[int](ErrFull).Unwrap(); err != ErrFull {
+		t.Fatal("Expected a failed result.")
+	}
+}
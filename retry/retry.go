@@ -0,0 +1,140 @@
+// Package retry provides a work queue that retries failed items after an exponential backoff.
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/delayqueue"
+	log "github.com/sirupsen/logrus"
+)
+
+// Policy describes how failed items are retried.
+type Policy struct {
+	Initial     time.Duration // Delay before the first retry
+	Max         time.Duration // Upper bound of the delay, or zero for no bound
+	Multiplier  float64       // Factor the delay grows by with every retry, or 2 if zero
+	MaxAttempts int           // Number of attempts before giving up on an item, or zero to retry forever
+}
+
+// Backoff returns the delay after the given failed attempt, starting from 1.
+func (p Policy) Backoff(attempt int) time.Duration {
+	m := p.Multiplier
+	if m == 0 {
+		m = 2
+	}
+
+	d := float64(p.Initial)
+	for i := 1; i < attempt; i++ {
+		d *= m
+		if p.Max > 0 && d >= float64(p.Max) {
+			return p.Max
+		}
+	}
+	return time.Duration(d)
+}
+
+// Failure is an item that ran out of attempts.
+type Failure[T any] struct {
+	Item     T
+	Attempts int
+	Err      error // Error of the last attempt
+}
+
+// Option configures a Queue.
+type Option[T any] func(*Queue[T])
+
+// OnGiveUp makes the queue call f with every item that runs out of attempts.
+// By default those items are dropped.
+func OnGiveUp[T any](f func(Failure[T])) Option[T] {
+	return func(q *Queue[T]) {
+		q.giveUp = f
+	}
+}
+
+// WithClock makes the queue time backoff delays with c instead of the real clock.
+func WithClock[T any](c clock.Clock) Option[T] {
+	return func(q *Queue[T]) {
+		q.clock = c
+	}
+}
+
+// Queue is a work queue where items whose handler fails are put back after a backoff delay.
+type Queue[T any] struct {
+	items  *delayqueue.Queue[*task[T]]
+	policy Policy
+	giveUp func(Failure[T])
+	clock  clock.Clock
+}
+
+type task[T any] struct {
+	item     T
+	attempts int
+}
+
+// New creates an empty Queue that retries items according to p.
+func New[T any](p Policy, opts ...Option[T]) *Queue[T] {
+	q := &Queue[T]{
+		policy: p,
+		clock:  clock.Real,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	q.items = delayqueue.New[*task[T]](delayqueue.WithClock(q.clock))
+	return q
+}
+
+// Enqueue adds items to be handled right away.
+func (q *Queue[T]) Enqueue(items ...T) {
+	now := q.clock.Now()
+	for _, item := range items {
+		q.items.Enqueue(&task[T]{item: item}, now)
+	}
+}
+
+// Len returns the number of items in the queue, including those waiting to be retried.
+func (q *Queue[T]) Len() int {
+	return q.items.Len()
+}
+
+// Close marks the queue as closed. Process returns once the items already in the queue
+// have been handled or given up on.
+func (q *Queue[T]) Close() {
+	q.items.Close()
+}
+
+// Process calls handle with items as they become due, until the queue is closed and empty
+// or ctx is done. Items for which handle returns an error are retried according to the policy.
+func (q *Queue[T]) Process(ctx context.Context, handle func(context.Context, T) error) error {
+	for {
+		tasks, err := q.items.Wait(ctx, 1)
+		if errors.Is(err, delayqueue.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		t := tasks[0]
+		t.attempts++
+
+		err = handle(ctx, t.item)
+		if err == nil {
+			continue
+		}
+
+		if q.policy.MaxAttempts > 0 && t.attempts >= q.policy.MaxAttempts {
+			log.Debugf("Giving up on item after %d attempts: %v", t.attempts, err)
+			if q.giveUp != nil {
+				q.giveUp(Failure[T]{Item: t.item, Attempts: t.attempts, Err: err})
+			}
+			continue
+		}
+
+		// Put it back to be retried after the backoff
+		q.items.EnqueueAfter(t, q.policy.Backoff(t.attempts))
+	}
+}
@@ -0,0 +1,100 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestBackoff(t *testing.T) {
+	p := Policy{Initial: time.Second, Max: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, e := range expected {
+		if d := p.Backoff(i + 1); d != e {
+			t.Fatalf("Expected backoff %v after attempt %d, got %v.", e, i+1, d)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	var failures []Failure[string]
+	q := New(Policy{Initial: time.Millisecond, MaxAttempts: 3}, OnGiveUp(func(f Failure[string]) {
+		failures = append(failures, f)
+	}))
+	q.Enqueue("flaky", "broken", "fine")
+
+	boom := errors.New("boom")
+	attempts := map[string]int{}
+	handle := func(_ context.Context, item string) error {
+		attempts[item]++
+		switch {
+		case item == "flaky" && attempts[item] < 2:
+			return boom
+		case item == "broken":
+			if attempts[item] == 3 {
+				// Items already in the queue are still handled after closing.
+				q.Close()
+			}
+			return boom
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := q.Process(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts["flaky"] != 2 || attempts["fine"] != 1 {
+		t.Fatalf("Unexpected attempts %v.", attempts)
+	}
+	if len(failures) != 1 || failures[0].Item != "broken" || failures[0].Attempts != 3 || failures[0].Err != boom {
+		t.Fatalf("Unexpected failures %+v.", failures)
+	}
+}
+
+func TestBackoffClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	q := New(Policy{Initial: time.Minute}, WithClock[string](c))
+	q.Enqueue("flaky")
+
+	attempts := make(chan int, 2)
+	n := 0
+	done := make(chan error)
+	go func() {
+		done <- q.Process(context.Background(), func(context.Context, string) error {
+			n++
+			attempts <- n
+			if n == 1 {
+				return errors.New("boom")
+			}
+			q.Close()
+			return nil
+		})
+	}()
+
+	<-attempts
+	// Wait for the retry to be scheduled on the clock.
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(59 * time.Second)
+	select {
+	case <-attempts:
+		t.Fatal("Expected the retry to wait for the backoff.")
+	default:
+	}
+
+	c.Advance(time.Second)
+	if a := <-attempts; a != 2 {
+		t.Fatalf("Expected a second attempt, got %d.", a)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+package cirque
+
+import "time"
+
+// Sequenced is an item along with its sequence number.
+type Sequenced[T any] struct {
+	Seq  uint64
+	Item T
+}
+
+// EnqueueSeq is like Enqueue, but also returns the sequence number assigned to each element.
+// Sequence numbers start from 1 and increase by one with every item admitted into the queue,
+// so elements rejected by an option or by a closed queue get 0.
+func (cq *Cirque[T]) EnqueueSeq(elements ...T) []uint64 {
+	seqs := make([]uint64, len(elements))
+	if cq.bound.limit > 0 {
+		cq.enqueueBounded(elements, seqs, time.Time{})
+		return seqs
+	}
+	if _, err := cq.enqueue(elements, seqs, time.Time{}); err != nil {
+		cq.reject(elements, err)
+	}
+	return seqs
+}
+
+// DequeueSeq is like Dequeue, but returns every item along with its sequence number.
+// Discarded items keep their sequence numbers, so the numbers returned may have gaps.
+func (cq *Cirque[T]) DequeueSeq(n int) []Sequenced[T] {
+	var result []Sequenced[T]
+
+	cq.take(n, func(seq uint64, item T, _ any) {
+		result = append(result, Sequenced[T]{Seq: seq, Item: item})
+	})
+
+	return result
+}
@@ -0,0 +1,62 @@
+package cirque
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSequenceNumbers(t *testing.T) {
+	cq := New[int](2)
+
+	seqs := cq.EnqueueSeq(10, 11, 12)
+	if len(seqs) != 3 || seqs[0] != 1 || seqs[2] != 3 {
+		t.Fatalf("Unexpected sequence numbers %v.", seqs)
+	}
+	cq.Enqueue(13)
+
+	cq.Discard(1)
+	items := cq.DequeueSeq(2)
+	if len(items) != 2 || items[0] != (Sequenced[int]{Seq: 2, Item: 11}) || items[1].Seq != 3 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	items = cq.DequeueSeq(5)
+	if len(items) != 1 || items[0] != (Sequenced[int]{Seq: 4, Item: 13}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestSequenceNumbersWithRejects(t *testing.T) {
+	cq := New(4, WithDedup(func(i int) int { return i }, 4, time.Minute))
+
+	seqs := cq.EnqueueSeq(1, 1, 2)
+	if seqs[0] != 1 || seqs[1] != 0 || seqs[2] != 2 {
+		t.Fatalf("Unexpected sequence numbers %v.", seqs)
+	}
+	if items := cq.DequeueSeq(2); items[1] != (Sequenced[int]{Seq: 2, Item: 2}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestSequenceNumbersBounded(t *testing.T) {
+	cq := New(4, WithBound[int](2))
+
+	if seqs := cq.EnqueueSeq(1, 2, 3, 4, 5); cq.Len() != 0 || seqs[0] != 0 {
+		t.Fatalf("Expected a batch over the bound to be rejected, got %v.", seqs)
+	}
+	if seqs := cq.EnqueueSeq(1, 2); seqs[0] != 1 || seqs[1] != 2 {
+		t.Fatalf("Unexpected sequence numbers %v.", seqs)
+	}
+	if err := cq.TryEnqueue(3); err != ErrFull {
+		t.Fatalf("Expected the queue to be full, got %v.", err)
+	}
+
+	if items := cq.Dequeue(5); len(items) != 2 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if err := cq.TryEnqueue(3, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := cq.TryEnqueue(5); err != ErrFull {
+		t.Fatalf("Expected the bound to hold after dequeuing, got %v.", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package slogring provides a slog.Handler that keeps recent low-level records in a ring buffer
+// and only emits them when an error occurs.
+package slogring
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/denis-ismailaj/cirque/history"
+)
+
+// Options configures a Handler.
+type Options struct {
+	// Level is the minimum level of records kept at all. Defaults to slog.LevelDebug.
+	Level slog.Leveler
+	// PassLevel is the minimum level of records handed to the underlying handler right away,
+	// instead of being kept in the ring. Defaults to slog.LevelInfo.
+	PassLevel slog.Leveler
+	// FlushLevel is the minimum level of records that flush the ring to the underlying handler
+	// before being handled themselves. Defaults to slog.LevelError.
+	FlushLevel slog.Leveler
+}
+
+// Handler is a slog.Handler that keeps the last n records below the pass level in a ring,
+// and hands all of them to the underlying handler, oldest first, when a record at the flush
+// level comes in. This gives always-on debug context for errors at the cost of a ring buffer.
+type Handler struct {
+	next  slog.Handler
+	opts  Options
+	state *state // Shared with handlers derived through WithAttrs and WithGroup
+}
+
+type state struct {
+	mu   sync.Mutex
+	ring *history.Buffer[entry]
+}
+
+// A kept record, along with the handler that would have handled it, which carries
+// the attributes and groups of the logger that created it.
+type entry struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// New creates a Handler in front of next that keeps the last n records.
+func New(next slog.Handler, n int, opts *Options) *Handler {
+	if n <= 0 {
+		return nil
+	}
+	h := &Handler{
+		next:  next,
+		state: &state{ring: history.New[entry](n)},
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelDebug
+	}
+	if h.opts.PassLevel == nil {
+		h.opts.PassLevel = slog.LevelInfo
+	}
+	if h.opts.FlushLevel == nil {
+		h.opts.FlushLevel = slog.LevelError
+	}
+	return h
+}
+
+// Enabled reports whether records of level are kept or passed on.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.opts.PassLevel.Level() {
+		return h.next.Enabled(ctx, level)
+	}
+	return level >= h.opts.Level.Level()
+}
+
+// Handle keeps r in the ring if it's below the pass level, and passes it on otherwise.
+// Records at the flush level first flush the ring.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.opts.PassLevel.Level() {
+		h.state.mu.Lock()
+		h.state.ring.Append(entry{handler: h.next, record: r.Clone()})
+		h.state.mu.Unlock()
+		return nil
+	}
+
+	if r.Level >= h.opts.FlushLevel.Level() {
+		if err := h.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// Flush hands all kept records to the underlying handler, oldest first, and empties the ring.
+// Records the underlying handler isn't enabled for are dropped.
+func (h *Handler) Flush(ctx context.Context) error {
+	h.state.mu.Lock()
+	entries := h.state.ring.Snapshot()
+	h.state.ring.Reset()
+	h.state.mu.Unlock()
+
+	for _, e := range entries {
+		if !e.handler.Enabled(ctx, e.record.Level) {
+			continue
+		}
+		if err := e.handler.Handle(ctx, e.record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithAttrs returns a Handler with the given attributes that shares the ring of h.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs), opts: h.opts, state: h.state}
+}
+
+// WithGroup returns a Handler with the given group that shares the ring of h.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), opts: h.opts, state: h.state}
+}
@@ -0,0 +1,41 @@
+package slogring
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFlushOnError(t *testing.T) {
+	var buf bytes.Buffer
+	next := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := slog.New(New(next, 2, nil))
+
+	logger.Debug("dropped")
+	logger.Debug("kept 1")
+	logger.With("conn", 7).Debug("kept 2")
+	logger.Info("passed")
+
+	if out := buf.String(); strings.Contains(out, "kept") || !strings.Contains(out, "passed") {
+		t.Fatalf("Expected only the info record before an error, got:\n%s", out)
+	}
+
+	logger.Error("failed")
+
+	out := buf.String()
+	if strings.Contains(out, "dropped") {
+		t.Fatalf("Expected the oldest record to be overwritten, got:\n%s", out)
+	}
+	k1, k2, e := strings.Index(out, "kept 1"), strings.Index(out, `"kept 2" conn=7`), strings.Index(out, "failed")
+	if k1 < 0 || k2 < k1 || e < k2 {
+		t.Fatalf("Expected kept records in order before the error, got:\n%s", out)
+	}
+
+	// The ring is empty after a flush.
+	buf.Reset()
+	logger.Error("failed again")
+	if strings.Contains(buf.String(), "kept") {
+		t.Fatalf("Expected records to be flushed only once, got:\n%s", buf.String())
+	}
+}
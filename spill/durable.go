@@ -0,0 +1,523 @@
+package spill
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/codec"
+	"github.com/denis-ismailaj/cirque/deque"
+	log "github.com/sirupsen/logrus"
+)
+
+// Default size at which segments of a Durable queue are rotated.
+const defaultSegmentSize = 1 << 20
+
+// Suffix of the blobs that record how far the consumer got.
+const offsetSuffix = ".off"
+
+// Option configures a Queue or a Durable queue.
+type Option func(*config)
+
+type config struct {
+	segmentSize  int64
+	sync         SyncPolicy
+	syncInterval time.Duration
+	aead         cipher.AEAD
+	clock        clock.Clock
+}
+
+// WithEncryption makes the queue encrypt every item with aead before it reaches the store,
+// for items that must not be stored in plaintext. The key of aead is up to the caller,
+// and items can only be read back with the same key.
+func WithEncryption(aead cipher.AEAD) Option {
+	return func(c *config) {
+		c.aead = aead
+	}
+}
+
+// Codec that a queue with the given config stores items with.
+func storedCodec[T any](c codec.Codec[T], cfg config) codec.Codec[T] {
+	if cfg.aead != nil {
+		return codec.Encrypted(c, cfg.aead)
+	}
+	return c
+}
+
+// WithSegmentSize makes a Durable queue start a new segment once the current one holds at least
+// size bytes of records. Smaller segments are deleted sooner once consumed, while larger ones
+// mean fewer blobs.
+func WithSegmentSize(size int64) Option {
+	return func(c *config) {
+		if size > 0 {
+			c.segmentSize = size
+		}
+	}
+}
+
+// Durable is a FIFO queue that keeps every item in a Store, so that items survive restarts.
+//
+// Items are addressed by an offset that increases with every enqueued item. Items are appended
+// to the current segment until it reaches the segment size, after which a new one is started.
+// Every segment is named after the offset of its first item, and every Dequeue records the offset
+// of the next item to dequeue, so the consumer resumes where it left off after a restart.
+// Fully consumed segments are deleted, so that the store only holds the live backlog.
+//
+// Segments are written as a stream, so a store that only makes blobs visible once their writer is
+// closed, like most object stores, only persists the items of segments that were rotated or closed.
+// A store must not be shared between queues.
+type Durable[T any] struct {
+	mu       sync.Mutex
+	store    Store
+	codec    codec.Codec[T]
+	sealed   *deque.Deque[span] // Segments that are no longer written to, oldest first
+	active   *segmentWriter     // Segment being written to, if any
+	base     uint64             // Offset of the first item of the active segment
+	recent   *cirque.Cirque[T]  // Unconsumed items of the active segment
+	front    *cirque.Cirque[T]  // Items read back from the oldest sealed segment, starting at head
+	head     uint64             // Offset of the next item to dequeue
+	tail     uint64             // Offset of the next item to enqueue
+	recorded uint64             // Offset last recorded in the store
+	errors   uint64             // Number of failed store operations
+	lastErr  error              // Error of the last store operation, if it failed
+	closed   bool
+	done     chan struct{} // Closed when the queue is closed
+	config
+}
+
+// span is the range of offsets of a sealed segment.
+type span struct {
+	base  uint64
+	count int
+}
+
+// OpenDurable opens a Durable queue over the items already in store, encoded with c.
+// Segments left partially consumed by a previous run are compacted, so that they only hold
+// the items that weren't consumed.
+func OpenDurable[T any](store Store, c codec.Codec[T], opts ...Option) (*Durable[T], error) {
+	q := &Durable[T]{
+		store:  store,
+		codec:  c,
+		sealed: deque.New[span](1),
+		recent: cirque.New[T](1),
+		front:  cirque.New[T](1),
+		done:   make(chan struct{}),
+		config: config{segmentSize: defaultSegmentSize, syncInterval: defaultSyncInterval, clock: clock.Real},
+	}
+	for _, opt := range opts {
+		opt(&q.config)
+	}
+	q.codec = storedCodec(c, q.config)
+
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+	q.recorded = q.head
+	q.base = q.tail
+
+	if q.sync == SyncInterval {
+		go q.syncEvery(q.syncInterval)
+	}
+
+	log.Debugf("Opened durable queue with %d items.", q.tail-q.head)
+	return q, nil
+}
+
+// Parse the offset out of a blob name with the given suffix.
+func parseOffset(name, suffix string) (uint64, bool) {
+	hex, ok := strings.CutSuffix(name, suffix)
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.ParseUint(hex, 16, 64)
+	return offset, err == nil
+}
+
+// Find the segments and the recorded offset in the store, deleting what was already consumed.
+func (q *Durable[T]) recover() error {
+	names, err := q.store.List()
+	if err != nil {
+		return err
+	}
+
+	// The latest recorded offset wins, older ones may be left over from a crash.
+	var bases, offsets []uint64
+	for _, name := range names {
+		if base, ok := parseOffset(name, ".seg"); ok {
+			bases = append(bases, base)
+		} else if offset, ok := parseOffset(name, offsetSuffix); ok {
+			offsets = append(offsets, offset)
+			q.head = max(q.head, offset)
+		}
+	}
+	for _, offset := range offsets {
+		if offset != q.head {
+			if err := q.store.Remove(offsetName(offset)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if bases, err = q.resolveCompaction(bases); err != nil {
+		return err
+	}
+
+	// Each segment runs up to the next one, and the last one up to its last record.
+	for i, base := range bases {
+		var count int
+		if i+1 < len(bases) {
+			count = int(bases[i+1] - base)
+		} else {
+			records, err := readSegment(q.store, segmentName(base))
+			if errors.Is(err, ErrCorrupt) {
+				log.Warningf("Segment %s was cut short, keeping its first %d items.", segmentName(base), len(records))
+			} else if err != nil {
+				return err
+			}
+			count = len(records)
+		}
+		q.tail = base + uint64(count)
+
+		if q.tail <= q.head {
+			if err := q.store.Remove(segmentName(base)); err != nil {
+				return err
+			}
+			log.Debugf("Deleted consumed segment %s.", segmentName(base))
+			continue
+		}
+		// The last segment may have been created without anything written to it before a crash.
+		// It has to go, or the next segment would be created under the same name.
+		if count == 0 {
+			if err := q.store.Remove(segmentName(base)); err != nil {
+				return err
+			}
+			log.Debugf("Deleted empty segment %s.", segmentName(base))
+			continue
+		}
+		q.sealed.PushBack(span{base: base, count: count})
+	}
+	q.tail = max(q.tail, q.head)
+
+	return q.compact()
+}
+
+// Find out whether a compaction was cut short, given the bases of the segments in the store, and
+// return the bases of the segments to keep. The old segment only counts as superseded once the new
+// one holds every record it was meant to; otherwise the new one is deleted and compaction starts over.
+func (q *Durable[T]) resolveCompaction(bases []uint64) ([]uint64, error) {
+	i := slices.Index(bases, q.head)
+	if i < 1 {
+		return bases, nil
+	}
+	base := bases[i-1]
+
+	// A segment that holds nothing past the head was consumed, not compacted.
+	records, err := readSegment(q.store, segmentName(base))
+	if err != nil && !errors.Is(err, ErrCorrupt) {
+		return nil, err
+	}
+	skip := int(q.head - base)
+	if len(records) <= skip {
+		return bases, nil
+	}
+
+	compacted, err := readSegment(q.store, segmentName(q.head))
+	if err != nil && !errors.Is(err, ErrCorrupt) {
+		return nil, err
+	}
+	if len(compacted) >= len(records)-skip {
+		return bases, nil
+	}
+
+	log.Warningf("Compaction of segment %s into %s was cut short, starting over.", segmentName(base), segmentName(q.head))
+	if err := q.store.Remove(segmentName(q.head)); err != nil {
+		return nil, err
+	}
+	return slices.Delete(bases, i, i+1), nil
+}
+
+// Rewrite the oldest segment if it was partially consumed, so that it only holds unconsumed items.
+// The new segment is named after the first of them, which keeps it in order before the others.
+// The old segment is only removed once the new one is complete, and if the process crashes before,
+// resolveCompaction sorts out which of them to keep on the next start.
+func (q *Durable[T]) compact() error {
+	s, ok := q.sealed.Front()
+	if !ok || s.base >= q.head {
+		return nil
+	}
+
+	records, err := readSegment(q.store, segmentName(s.base))
+	if len(records) < s.count {
+		return errors.Join(ErrCorrupt, err)
+	}
+	skip := int(q.head - s.base)
+	if err := writeSegment(q.store, segmentName(q.head), records[skip:s.count]); err != nil {
+		return err
+	}
+	// If this fails, the old segment is deleted as superseded on the next start.
+	if err := q.store.Remove(segmentName(s.base)); err != nil {
+		return err
+	}
+
+	q.sealed.PopFront()
+	q.sealed.PushFront(span{base: q.head, count: s.count - skip})
+	log.Debugf("Compacted segment %s into %s.", segmentName(s.base), segmentName(q.head))
+	return nil
+}
+
+func offsetName(offset uint64) string {
+	return fmt.Sprintf("%016x%s", offset, offsetSuffix)
+}
+
+// Len returns the number of items in the queue.
+func (q *Durable[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return int(q.tail - q.head)
+}
+
+// Enqueue appends items to the queue. If any of them can't be encoded, none of them are enqueued.
+func (q *Durable[T]) Enqueue(items ...T) error {
+	records := make([][]byte, len(items))
+	for i, item := range items {
+		data, err := q.codec.Encode(item)
+		if err != nil {
+			return err
+		}
+		records[i] = data
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	return q.track(q.write(items, records))
+}
+
+// Append records of items to the active segment, rotating it as needed.
+// Items only join the queue once their records were written out to the store, so if that fails,
+// none of the items that weren't are enqueued, and the next write starts a new segment.
+// Must be called with mu held.
+func (q *Durable[T]) write(items []T, records [][]byte) error {
+	from := 0 // First of the items not written out yet
+	for i, data := range records {
+		if q.active == nil {
+			var err error
+			if q.active, err = createSegment(q.store, segmentName(q.tail)); err != nil {
+				return err
+			}
+			q.base = q.tail
+		}
+		if err := q.active.append(data); err != nil {
+			return q.abort(err)
+		}
+
+		if q.sync == SyncWrite {
+			if err := q.active.sync(); err != nil {
+				return q.abort(err)
+			}
+			q.commit(items[from : i+1])
+			from = i + 1
+		}
+
+		if q.active.size >= q.segmentSize {
+			if err := q.rotate(items[from : i+1]); err != nil {
+				return err
+			}
+			from = i + 1
+		}
+	}
+
+	if q.active == nil {
+		return nil
+	}
+	var err error
+	if q.sync == SyncBatch {
+		err = q.active.sync()
+	} else {
+		err = q.active.flush()
+	}
+	if err != nil {
+		return q.abort(err)
+	}
+	q.commit(items[from:])
+	return nil
+}
+
+// Add items whose records were written out to the active segment to the queue.
+// Must be called with mu held.
+func (q *Durable[T]) commit(items []T) {
+	q.recent.Enqueue(items...)
+	q.tail += uint64(len(items))
+}
+
+// Seal the active segment once the pending items appended to it are written out, adding them
+// to the queue. If that fails, they are dropped like by abort.
+// Must be called with mu held.
+func (q *Durable[T]) rotate(pending []T) error {
+	var err error
+	if q.sync != SyncNever {
+		err = q.active.sync()
+	}
+	if err == nil {
+		err = q.active.close()
+	}
+	if err != nil {
+		return q.abort(err)
+	}
+	q.active = nil
+	q.commit(pending)
+	q.seal()
+	return q.deleteConsumed()
+}
+
+// Give up on the active segment after a failed write, since its buffer keeps failing once a write
+// did, and seal it with the items that were written out before. The records appended after them are
+// dropped, although some may have reached the store; they are ignored once a later segment exists.
+// Must be called with mu held.
+func (q *Durable[T]) abort(err error) error {
+	log.Warningf("Dropping the active segment %s after a failed write: %v.", segmentName(q.base), err)
+	q.active.f.Close()
+	q.active = nil
+
+	// A segment without any items is deleted, so that it isn't mistaken for the next one.
+	if q.tail == q.base {
+		return errors.Join(err, q.store.Remove(segmentName(q.base)))
+	}
+	q.seal()
+	return errors.Join(err, q.deleteConsumed())
+}
+
+// Add the active segment, which was closed, to the sealed ones.
+// Must be called with mu held.
+func (q *Durable[T]) seal() {
+	q.sealed.PushBack(span{base: q.base, count: int(q.tail - q.base)})
+
+	// If the consumer is already within the segment, keep serving it from memory,
+	// otherwise it is read back once the consumer gets to it.
+	if q.head > q.base {
+		q.front.Enqueue(q.recent.Dequeue(q.recent.Len())...)
+	} else {
+		q.recent.Discard(q.recent.Len())
+	}
+
+	log.Debugf("Rotated segment %s.", segmentName(q.base))
+	q.base = q.tail
+}
+
+// Dequeue returns a maximum of n items from the queue and records how far the consumer got,
+// deleting segments that were fully consumed.
+func (q *Durable[T]) Dequeue(n int) ([]T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrClosed
+	}
+
+	var result []T
+	for len(result) < n && q.head < q.tail {
+		if q.front.Len() == 0 && q.head < q.base {
+			if err := q.load(); err != nil {
+				return result, q.track(err)
+			}
+		}
+
+		var items []T
+		if q.head < q.base {
+			items = q.front.Dequeue(n - len(result))
+		} else {
+			items = q.recent.Dequeue(n - len(result))
+		}
+		result = append(result, items...)
+		q.head += uint64(len(items))
+
+		if err := q.deleteConsumed(); err != nil {
+			return result, q.track(err)
+		}
+	}
+
+	return result, q.track(q.record())
+}
+
+// Read the oldest sealed segment back into the front, from head on.
+// Must be called with mu held.
+func (q *Durable[T]) load() error {
+	s, _ := q.sealed.Front()
+	records, err := readSegment(q.store, segmentName(s.base))
+	if len(records) < s.count {
+		return errors.Join(ErrCorrupt, err)
+	}
+	for _, r := range records[q.head-s.base : s.count] {
+		item, err := q.codec.Decode(r)
+		if err != nil {
+			return err
+		}
+		q.front.Enqueue(item)
+	}
+
+	log.Debugf("Read back %d items from %s.", q.front.Len(), segmentName(s.base))
+	return nil
+}
+
+// Must be called with mu held.
+func (q *Durable[T]) deleteConsumed() error {
+	for {
+		s, ok := q.sealed.Front()
+		if !ok || s.base+uint64(s.count) > q.head {
+			return nil
+		}
+		if err := q.store.Remove(segmentName(s.base)); err != nil {
+			return err
+		}
+		q.sealed.PopFront()
+		log.Debugf("Deleted consumed segment %s.", segmentName(s.base))
+	}
+}
+
+// Record the offset of the next item to dequeue, replacing the previous record.
+// Must be called with mu held.
+func (q *Durable[T]) record() error {
+	if q.head == q.recorded {
+		return nil
+	}
+	w, err := q.store.Create(offsetName(q.head))
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	// If this fails, the older record is deleted on the next start.
+	if err := q.store.Remove(offsetName(q.recorded)); err != nil && q.recorded != 0 {
+		return err
+	}
+	q.recorded = q.head
+	return nil
+}
+
+// Close seals the active segment. Items stay in the store for the queue to be opened again.
+func (q *Durable[T]) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+
+	if q.active == nil {
+		return nil
+	}
+	return q.rotate(nil)
+}
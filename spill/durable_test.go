@@ -0,0 +1,210 @@
+package spill
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+func countSegments(t *testing.T, store Store) int {
+	names, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, name := range names {
+		if strings.HasSuffix(name, ".seg") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDurableRotation(t *testing.T) {
+	store := &memStore{blobs: make(map[string][]byte)}
+	// Every JSON-encoded digit takes 9 bytes with its header, so segments rotate every 2 items.
+	q, err := OpenDurable(store, codec.JSON[int](), WithSegmentSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Enqueue(0, 1, 2, 3, 4, 5); err != nil {
+		t.Fatal(err)
+	}
+	if countSegments(t, store) != 3 {
+		t.Fatalf("Expected 3 segments, got %d.", countSegments(t, store))
+	}
+
+	items, err := q.Dequeue(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []int{0, 1, 2}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if countSegments(t, store) != 2 {
+		t.Fatal("Expected the consumed segment to be deleted.")
+	}
+
+	items, _ = q.Dequeue(10)
+	if !slices.Equal(items, []int{3, 4, 5}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if countSegments(t, store) != 0 || q.Len() != 0 {
+		t.Fatal("Expected every segment to be deleted.")
+	}
+}
+
+func TestDurableReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := OpenDurable(store, codec.JSON[int](), WithSegmentSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(0, 1, 2, 3, 4, 5, 6); err != nil {
+		t.Fatal(err)
+	}
+	// Stop halfway through the second segment.
+	if _, err := q.Dequeue(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err = OpenDurable(store, codec.JSON[int](), WithSegmentSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 4 {
+		t.Fatalf("Expected 4 items after reopening, got %d.", q.Len())
+	}
+	if _, err := os.Stat(filepath.Join(dir, segmentName(2))); !os.IsNotExist(err) {
+		t.Fatal("Expected the partially consumed segment to be compacted.")
+	}
+
+	if err := q.Enqueue(7); err != nil {
+		t.Fatal(err)
+	}
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []int{3, 4, 5, 6, 7}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestDurableTruncated(t *testing.T) {
+	store := &memStore{blobs: make(map[string][]byte)}
+	if err := writeSegment(store, segmentName(0), [][]byte{[]byte("1"), []byte("2")}); err != nil {
+		t.Fatal(err)
+	}
+	// Cut the last record short, like a crash in the middle of a write would.
+	blob := store.blobs[segmentName(0)]
+	store.blobs[segmentName(0)] = blob[:len(blob)-1]
+
+	q, err := OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(3); err != nil {
+		t.Fatal(err)
+	}
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []int{1, 3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestDurableEmptySegment(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSegment(store, segmentName(0), [][]byte{[]byte("1"), []byte("2")}); err != nil {
+		t.Fatal(err)
+	}
+	// Leave the next segment empty, like a crash right after creating it would.
+	if err := os.WriteFile(filepath.Join(dir, segmentName(2)), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(3, 4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err = OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []int{4}) {
+		t.Fatalf("Expected the last item to survive, got %v.", items)
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memStore{blobs: make(map[string][]byte)}
+	q, err := OpenDurable(store, codec.JSON[string](), WithEncryption(aead))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, blob := range store.blobs {
+		if bytes.Contains(blob, []byte("secret")) {
+			t.Fatal("Expected items to be stored encrypted.")
+		}
+	}
+
+	q, err = OpenDurable(store, codec.JSON[string](), WithEncryption(aead))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items, err := q.Dequeue(1); err != nil || !slices.Equal(items, []string{"secret"}) {
+		t.Fatalf("Unexpected items %v: %v.", items, err)
+	}
+}
@@ -0,0 +1,148 @@
+package spill
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrInjected is the error returned by operations failed by a FaultStore, unless another one is given.
+var ErrInjected = errors.New("spill: injected fault")
+
+// Op is a kind of store operation that a FaultStore can fail.
+type Op int
+
+const (
+	// OpCreate is the creation of a blob.
+	OpCreate Op = iota
+	// OpWrite is a write to a blob. Queues buffer records, so this is a flush rather than a record.
+	OpWrite
+	// OpSync is a sync of a blob to stable storage.
+	OpSync
+	// OpRemove is the deletion of a blob.
+	OpRemove
+)
+
+// FaultStore wraps a Store and fails chosen operations, so that applications can test how they
+// recover from failing or crashing storage without mocking the filesystem themselves.
+// Faults are armed for the n-th operation of a kind from the moment they are set, and fire once.
+type FaultStore struct {
+	Store
+	mu     sync.Mutex
+	counts map[Op]int     // Number of operations of every kind so far
+	faults map[Op][]fault // Armed faults of every kind
+}
+
+type fault struct {
+	at   int   // Count of the operation to fail
+	err  error // Error to fail it with
+	keep int   // Bytes of a write to keep before failing it, or -1 to keep none
+}
+
+// WithFaults wraps store in a FaultStore with no faults armed.
+func WithFaults(store Store) *FaultStore {
+	return &FaultStore{
+		Store:  store,
+		counts: make(map[Op]int),
+		faults: make(map[Op][]fault),
+	}
+}
+
+// Fail makes the n-th operation of kind op from now on fail with err, or with ErrInjected if err is nil.
+func (s *FaultStore) Fail(op Op, n int, err error) {
+	if err == nil {
+		err = ErrInjected
+	}
+	s.arm(op, fault{at: n, err: err, keep: -1})
+}
+
+// Tear makes the n-th write from now on only write its first keep bytes before failing with
+// ErrInjected, like a write cut short by a crash.
+func (s *FaultStore) Tear(n, keep int) {
+	s.arm(OpWrite, fault{at: n, err: ErrInjected, keep: max(keep, 0)})
+}
+
+func (s *FaultStore) arm(op Op, f fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f.at += s.counts[op]
+	s.faults[op] = append(s.faults[op], f)
+}
+
+// Count an operation of kind op, and return the fault armed for it, if any.
+func (s *FaultStore) next(op Op) (fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts[op]++
+	for i, f := range s.faults[op] {
+		if f.at == s.counts[op] {
+			s.faults[op] = append(s.faults[op][:i], s.faults[op][i+1:]...)
+			log.Debugf("Injecting fault into operation %d of kind %d.", f.at, op)
+			return f, true
+		}
+	}
+	return fault{}, false
+}
+
+// Create creates a blob in the wrapped store, unless a fault is armed for it.
+func (s *FaultStore) Create(name string) (io.WriteCloser, error) {
+	if f, ok := s.next(OpCreate); ok {
+		return nil, f.err
+	}
+	w, err := s.Store.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &faultWriter{WriteCloser: w, store: s}, nil
+}
+
+// Remove deletes a blob from the wrapped store, unless a fault is armed for it.
+func (s *FaultStore) Remove(name string) error {
+	if f, ok := s.next(OpRemove); ok {
+		return f.err
+	}
+	return s.Store.Remove(name)
+}
+
+// Size tells the size of a blob if the wrapped store is a Sizer.
+func (s *FaultStore) Size(name string) (int64, error) {
+	if sizer, ok := s.Store.(Sizer); ok {
+		return sizer.Size(name)
+	}
+	return 0, errors.ErrUnsupported
+}
+
+// faultWriter is a blob writer of a FaultStore.
+type faultWriter struct {
+	io.WriteCloser
+	store *FaultStore
+}
+
+func (w *faultWriter) Write(p []byte) (int, error) {
+	f, ok := w.store.next(OpWrite)
+	if !ok {
+		return w.WriteCloser.Write(p)
+	}
+	if f.keep <= 0 {
+		return 0, f.err
+	}
+	n, err := w.WriteCloser.Write(p[:min(f.keep, len(p))])
+	if err != nil {
+		return n, err
+	}
+	return n, f.err
+}
+
+func (w *faultWriter) Sync() error {
+	if f, ok := w.store.next(OpSync); ok {
+		return f.err
+	}
+	if s, ok := w.WriteCloser.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
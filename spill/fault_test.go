@@ -0,0 +1,163 @@
+package spill
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+func TestFaultTear(t *testing.T) {
+	dir, err := Dir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := WithFaults(dir)
+	q, err := OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(1); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Tear(1, 3)
+	if err := q.Enqueue(2); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Expected an injected fault, got %v.", err)
+	}
+
+	// Reopen without closing, as after a crash.
+	q, err = OpenDurable(dir, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != 1 {
+		t.Fatalf("Expected only the intact item to be recovered, got %v.", items)
+	}
+}
+
+func TestFaultWrite(t *testing.T) {
+	dir, err := Dir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := WithFaults(dir)
+	q, err := OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(1); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Fail(OpWrite, 1, nil)
+	if err := q.Enqueue(2); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Expected an injected fault, got %v.", err)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("Expected the failed item not to be enqueued, got %d items.", q.Len())
+	}
+
+	// The queue recovers as soon as the store does.
+	if err := q.Enqueue(3); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := q.Health(); err != nil || h.Errors != 1 {
+		t.Fatalf("Expected the queue to be healthy again, got %+v and %v.", h, err)
+	}
+
+	// Reopen without closing, as after a crash.
+	q, err = OpenDurable(dir, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []int{1, 3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestFaultSync(t *testing.T) {
+	dir, err := Dir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := WithFaults(dir)
+	q, err := OpenDurable(store, codec.JSON[int](), WithSync(SyncWrite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	store.Fail(OpSync, 2, nil)
+	if err := q.Enqueue(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(2); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Expected an injected fault, got %v.", err)
+	}
+	if err := q.Enqueue(3); err != nil {
+		t.Fatal("Expected the fault to fire only once.")
+	}
+
+	h, err := q.Health()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Errors != 1 {
+		t.Fatalf("Expected 1 error, got %d.", h.Errors)
+	}
+}
+
+func TestFaultCompaction(t *testing.T) {
+	dir, err := Dir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := OpenDurable(dir, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(0, 1, 2, 3, 4, 5, 6, 7, 8, 9); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash in the middle of writing the compacted segment.
+	store := WithFaults(dir)
+	store.Tear(1, 10)
+	if _, err := OpenDurable(store, codec.JSON[int]()); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Expected an injected fault, got %v.", err)
+	}
+
+	q, err = OpenDurable(dir, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []int{3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatalf("Expected the unconsumed items to survive, got %v.", items)
+	}
+}
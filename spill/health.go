@@ -0,0 +1,63 @@
+package spill
+
+import "fmt"
+
+// Health describes the state of a Durable queue, for readiness probes and dashboards.
+type Health struct {
+	Items     int    `json:"items"`
+	Segments  int    `json:"segments"`
+	Bytes     int64  `json:"bytes"`                // Space taken in the store, or -1 if the store isn't a Sizer
+	Errors    uint64 `json:"errors"`               // Number of failed store operations since the queue was opened
+	LastError string `json:"last_error,omitempty"` // Error of the last store operation, if it failed
+}
+
+// Health reports the state of the queue, along with an error if the queue is closed or its
+// last store operation failed. A queue recovers as soon as a store operation succeeds again.
+func (q *Durable[T]) Health() (Health, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	h := Health{
+		Items:    int(q.tail - q.head),
+		Segments: q.sealed.Len(),
+		Errors:   q.errors,
+	}
+	var names []string
+	for _, s := range q.sealed.Snapshot() {
+		names = append(names, segmentName(s.base))
+	}
+	if q.active != nil {
+		h.Segments++
+		names = append(names, segmentName(q.base))
+	}
+
+	h.Bytes = -1
+	if sizer, ok := q.store.(Sizer); ok {
+		h.Bytes = 0
+		for _, name := range names {
+			// Segments that a store like an object store doesn't show before closing them count as empty.
+			if size, err := sizer.Size(name); err == nil {
+				h.Bytes += size
+			}
+		}
+	}
+
+	if q.closed {
+		return h, ErrClosed
+	}
+	if q.lastErr != nil {
+		h.LastError = q.lastErr.Error()
+		return h, fmt.Errorf("spill: last store operation failed: %w", q.lastErr)
+	}
+	return h, nil
+}
+
+// Keep track of the outcome of a store operation, and return its error.
+// Must be called with mu held.
+func (q *Durable[T]) track(err error) error {
+	if err != nil {
+		q.errors++
+	}
+	q.lastErr = err
+	return err
+}
@@ -0,0 +1,75 @@
+package spill
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+// failingStore fails to create blobs while failing is set.
+type failingStore struct {
+	*memStore
+	failing bool
+}
+
+func (s *failingStore) Create(name string) (io.WriteCloser, error) {
+	if s.failing {
+		return nil, errors.New("disk full")
+	}
+	return s.memStore.Create(name)
+}
+
+func TestHealth(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Dir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := OpenDurable(store, codec.JSON[int](), WithSegmentSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := q.Health()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Items != 3 || h.Segments != 2 || h.Bytes <= 0 || h.Errors != 0 {
+		t.Fatalf("Unexpected health %+v.", h)
+	}
+
+	q.Close()
+	if _, err := q.Health(); err != ErrClosed {
+		t.Fatal("Expected a closed queue to be unhealthy.")
+	}
+}
+
+func TestHealthErrors(t *testing.T) {
+	store := &failingStore{memStore: &memStore{blobs: make(map[string][]byte)}, failing: true}
+	q, err := OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Enqueue(1); err == nil {
+		t.Fatal("Expected the write to fail.")
+	}
+	h, err := q.Health()
+	if err == nil || h.Errors != 1 || h.LastError != "disk full" || h.Bytes != -1 {
+		t.Fatalf("Unexpected health %+v.", h)
+	}
+
+	// The queue recovers once the store does.
+	store.failing = false
+	if err := q.Enqueue(1); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := q.Health(); err != nil || h.Errors != 1 {
+		t.Fatalf("Expected the queue to recover, got %+v.", h)
+	}
+}
@@ -0,0 +1,113 @@
+package spill
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+// SegmentInfo describes a segment found in a store by Segments.
+type SegmentInfo struct {
+	Name    string
+	Version int   // Version of the segment format
+	Records int   // Number of intact records
+	Size    int64 // Size of the intact records, without headers
+	Err     error // ErrCorrupt if the segment ends with a damaged or cut record, or ErrVersion
+}
+
+// Segments describes the segments in store, in order, for tools that inspect queues offline.
+// Unlike errors reading the store, damaged segments don't stop it.
+func Segments(store Store) ([]SegmentInfo, error) {
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []SegmentInfo
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".seg") {
+			continue
+		}
+		v, records, err := readVersioned(store, name)
+		if err != nil && err != ErrCorrupt && err != ErrVersion {
+			return infos, err
+		}
+		info := SegmentInfo{Name: name, Version: int(v), Records: len(records), Err: err}
+		for _, r := range records {
+			info.Size += int64(len(r))
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Records returns the intact records of the segment with the given name, still encoded with the codec
+// of the queue, along with ErrCorrupt if the segment ends with a damaged or cut record.
+func Records(store Store, name string) ([][]byte, error) {
+	return readSegment(store, name)
+}
+
+// Consumed returns the offset up to which the consumer of a Durable queue in store got,
+// and false if it has no record of one.
+func Consumed(store Store) (uint64, bool, error) {
+	names, err := store.List()
+	if err != nil {
+		return 0, false, err
+	}
+	var offset uint64
+	found := false
+	for _, name := range names {
+		if o, ok := parseOffset(name, offsetSuffix); ok {
+			offset, found = max(offset, o), true
+		}
+	}
+	return offset, found, nil
+}
+
+// repairSuffix names the copy of a segment that Repair keeps while rewriting it.
+const repairSuffix = ".repair"
+
+// Repair rewrites the segment with the given name to hold only its intact records, in the current
+// version, and returns how many it kept. The records are first written to a copy of the segment,
+// which is only removed once the segment was written again, so if Repair is cut short, calling it
+// again finishes the job from the copy; see Interrupted. Repair must not be used while a queue is
+// using store.
+func Repair(store Store, name string) (int, error) {
+	backup := name + repairSuffix
+
+	// A complete copy left by an earlier attempt holds every intact record, whatever became of the segment.
+	records, err := readSegment(store, backup)
+	if err != nil {
+		records, err = readSegment(store, name)
+		if err != nil && err != ErrCorrupt {
+			return 0, err
+		}
+		if err := writeSegment(store, backup, records); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := store.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	if err := writeSegment(store, name, records); err != nil {
+		return 0, err
+	}
+	return len(records), store.Remove(backup)
+}
+
+// Interrupted returns the names of the segments whose Repair was cut short, which have to be
+// repaired again before a queue uses store.
+func Interrupted(store Store) ([]string, error) {
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, name := range names {
+		if segment, ok := strings.CutSuffix(name, repairSuffix); ok {
+			result = append(result, segment)
+		}
+	}
+	return result, nil
+}
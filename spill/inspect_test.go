@@ -0,0 +1,75 @@
+package spill
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+func TestInspect(t *testing.T) {
+	store := &memStore{blobs: make(map[string][]byte)}
+	q, err := OpenDurable(store, codec.JSON[int](), WithSegmentSize(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(1); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	if offset, ok, _ := Consumed(store); !ok || offset != 1 {
+		t.Fatalf("Unexpected consumed offset %d.", offset)
+	}
+
+	// Damage the last record of the first segment.
+	blob := store.blobs[segmentName(0)]
+	blob[len(blob)-1] ^= 1
+
+	infos, err := Segments(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 || infos[0].Records != 1 || infos[0].Err != ErrCorrupt || infos[1].Records != 1 || infos[1].Err != nil {
+		t.Fatalf("Unexpected segments %+v.", infos)
+	}
+
+	if n, err := Repair(store, segmentName(0)); err != nil || n != 1 {
+		t.Fatalf("Repair kept %d records: %v.", n, err)
+	}
+	if records, err := Records(store, segmentName(0)); err != nil || len(records) != 1 {
+		t.Fatal("Expected the segment to be repaired.")
+	}
+}
+
+func TestRepairInterrupted(t *testing.T) {
+	mem := &memStore{blobs: make(map[string][]byte)}
+	if err := writeSegment(mem, segmentName(0), [][]byte{[]byte("1"), []byte("2")}); err != nil {
+		t.Fatal(err)
+	}
+	blob := mem.blobs[segmentName(0)]
+	mem.blobs[segmentName(0)] = blob[:len(blob)-1]
+
+	// Fail to write the segment again, after its copy was written.
+	store := WithFaults(mem)
+	store.Fail(OpCreate, 2, nil)
+	if _, err := Repair(store, segmentName(0)); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Expected an injected fault, got %v.", err)
+	}
+	if names, _ := Interrupted(mem); len(names) != 1 || names[0] != segmentName(0) {
+		t.Fatalf("Expected the repair to be interrupted, got %v.", names)
+	}
+
+	if n, err := Repair(mem, segmentName(0)); err != nil || n != 1 {
+		t.Fatalf("Repair kept %d records: %v.", n, err)
+	}
+	if records, err := Records(mem, segmentName(0)); err != nil || len(records) != 1 {
+		t.Fatal("Expected the intact record to survive.")
+	}
+	if names, _ := Interrupted(mem); len(names) != 0 {
+		t.Fatalf("Expected the copy to be removed, got %v.", names)
+	}
+}
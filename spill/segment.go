@@ -0,0 +1,185 @@
+package spill
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// ErrCorrupt is returned when a segment can't be read back.
+var ErrCorrupt = errors.New("spill: corrupt segment")
+
+// ErrVersion is returned when a segment was written in a format newer than this package knows.
+var ErrVersion = errors.New("spill: unsupported segment version")
+
+// Segments start with magic followed by the version of their format, except for version 1
+// segments, which have no header. Readers understand every version up to the current one,
+// while writers always use the current one, so older segments are migrated as they get consumed
+// or compacted. A version 1 segment could only be mistaken for a newer one if its first record
+// were over a gigabyte long.
+//
+// Version 1: records with a 4 byte length prefix.
+// Version 2: records with a 4 byte length prefix and a 4 byte CRC-32 checksum.
+const (
+	magic   = "CIRQ"
+	version = 2
+)
+
+// Size of the length prefix and checksum of every record.
+const (
+	headerLen   = 4
+	checksumLen = 4
+)
+
+// segment is a blob holding a run of encoded items, each as a length-prefixed record.
+type segment struct {
+	name  string
+	count int // Number of records in the segment
+}
+
+// Name of the segment with the given ID, which sorts segments in the order they were written.
+func segmentName(id uint64) string {
+	return fmt.Sprintf("%016x.seg", id)
+}
+
+// Write records to a new segment.
+func writeSegment(store Store, name string, records [][]byte) (err error) {
+	w, err := createSegment(store, name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := w.close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	for _, r := range records {
+		if err := w.append(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// segmentWriter appends records to a segment that is being written.
+type segmentWriter struct {
+	f    io.WriteCloser
+	w    *bufio.Writer
+	size int64 // Number of bytes appended so far
+}
+
+func createSegment(store Store, name string) (*segmentWriter, error) {
+	f, err := store.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	s := &segmentWriter{f: f, w: bufio.NewWriter(f)}
+	s.w.WriteString(magic)
+	s.w.WriteByte(version)
+	return s, nil
+}
+
+// Append a record to the buffer of the segment.
+func (s *segmentWriter) append(record []byte) error {
+	var header [headerLen + checksumLen]byte
+	binary.BigEndian.PutUint32(header[:headerLen], uint32(len(record)))
+	binary.BigEndian.PutUint32(header[headerLen:], crc32.ChecksumIEEE(record))
+	if _, err := s.w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(record); err != nil {
+		return err
+	}
+	s.size += int64(len(header) + len(record))
+	return nil
+}
+
+// Pass the buffered records on to the store.
+func (s *segmentWriter) flush() error {
+	return s.w.Flush()
+}
+
+// Pass the buffered records on to the store, and sync them to stable storage if the store can.
+func (s *segmentWriter) sync() error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if f, ok := s.f.(syncer); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
+func (s *segmentWriter) close() error {
+	if err := s.flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// Read all records of a segment, in whichever version it was written. If the segment is cut short,
+// like when the process crashed while writing it, the records before the cut are returned along
+// with ErrCorrupt.
+func readSegment(store Store, name string) ([][]byte, error) {
+	_, records, err := readVersioned(store, name)
+	return records, err
+}
+
+// Read all records of a segment like readSegment, along with its version.
+func readVersioned(store Store, name string) (byte, [][]byte, error) {
+	f, err := store.Open(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	v := byte(1)
+	if header, err := r.Peek(len(magic) + 1); err == nil && string(header[:len(magic)]) == magic {
+		v = header[len(magic)]
+		r.Discard(len(header))
+	}
+
+	var records [][]byte
+	switch v {
+	case 1:
+		records, err = readRecords(r, false)
+	case 2:
+		records, err = readRecords(r, true)
+	default:
+		err = ErrVersion
+	}
+	return v, records, err
+}
+
+// Read records until the end of r, verifying their checksums if they have them.
+func readRecords(r io.Reader, checksums bool) ([][]byte, error) {
+	n := headerLen
+	if checksums {
+		n += checksumLen
+	}
+
+	var records [][]byte
+	header := make([]byte, n)
+	for {
+		if _, err := io.ReadFull(r, header); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, ErrCorrupt
+		}
+
+		record := make([]byte, binary.BigEndian.Uint32(header[:headerLen]))
+		if _, err := io.ReadFull(r, record); err != nil {
+			return records, ErrCorrupt
+		}
+		if checksums && crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(header[headerLen:]) {
+			return records, ErrCorrupt
+		}
+		records = append(records, record)
+	}
+}
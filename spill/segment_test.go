@@ -0,0 +1,67 @@
+package spill
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+// Encode records in the version 1 format, which has no header nor checksums.
+func encodeV1(records ...string) []byte {
+	var blob []byte
+	for _, r := range records {
+		blob = binary.BigEndian.AppendUint32(blob, uint32(len(r)))
+		blob = append(blob, r...)
+	}
+	return blob
+}
+
+func TestReadV1(t *testing.T) {
+	store := &memStore{blobs: map[string][]byte{segmentName(0): encodeV1("1", "2", "3")}}
+
+	q, err := OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Dequeue(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening compacts the segment, which migrates it to the current version.
+	q, err = OpenDurable(store, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blob := store.blobs[segmentName(1)]; string(blob[:len(magic)]) != magic || blob[len(magic)] != version {
+		t.Fatal("Expected the segment to be migrated.")
+	}
+	if items, err := q.Dequeue(10); err != nil || !slices.Equal(items, []int{2, 3}) {
+		t.Fatalf("Unexpected items %v: %v.", items, err)
+	}
+}
+
+func TestReadVersion(t *testing.T) {
+	store := &memStore{blobs: map[string][]byte{segmentName(0): append([]byte(magic), version+1)}}
+	if _, err := readSegment(store, segmentName(0)); err != ErrVersion {
+		t.Fatal("Expected a newer version to be rejected.")
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	store := &memStore{blobs: make(map[string][]byte)}
+	if err := writeSegment(store, segmentName(0), [][]byte{[]byte("a"), []byte("b")}); err != nil {
+		t.Fatal(err)
+	}
+	blob := store.blobs[segmentName(0)]
+	blob[len(blob)-1] ^= 1
+
+	records, err := readSegment(store, segmentName(0))
+	if err != ErrCorrupt || len(records) != 1 {
+		t.Fatal("Expected the flipped bit to be detected.")
+	}
+}
@@ -0,0 +1,207 @@
+// Package spill provides a queue that keeps its front and back in memory and spills the
+// middle to disk once it grows past a threshold, so that a long backlog doesn't exhaust memory.
+package spill
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/denis-ismailaj/cirque"
+	"github.com/denis-ismailaj/cirque/codec"
+	"github.com/denis-ismailaj/cirque/deque"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrClosed is returned when using a closed queue.
+var ErrClosed = errors.New("spill: queue closed")
+
+// Queue is a FIFO queue of items in three parts: the front is read from memory, the back is
+// written to memory, and whenever the items in memory exceed the threshold, the back is
+// written out as a segment file. Segments are read back into the front, oldest first, as the
+// consumer catches up.
+type Queue[T any] struct {
+	mu        sync.Mutex
+	store     Store
+	threshold int
+	codec     codec.Codec[T]
+	front     *cirque.Cirque[T]
+	back      *cirque.Cirque[T]
+	segments  *deque.Deque[segment] // Spilled segments, oldest first
+	spilled   int                   // Number of items in segments
+	nextID    uint64                // ID of the next segment file
+	closed    bool
+}
+
+// New creates a Queue that keeps at most threshold items in memory, and spills the rest
+// into segment files in dir, encoded with c. dir is created if it doesn't exist.
+// Of the options, only WithEncryption applies to a Queue.
+func New[T any](dir string, threshold int, c codec.Codec[T], opts ...Option) (*Queue[T], error) {
+	store, err := Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithStore(store, threshold, c, opts...), nil
+}
+
+// NewWithStore is like New, but spills segments into store.
+func NewWithStore[T any](store Store, threshold int, c codec.Codec[T], opts ...Option) *Queue[T] {
+	if threshold <= 0 {
+		return nil
+	}
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Queue[T]{
+		store:     store,
+		threshold: threshold,
+		codec:     storedCodec(c, cfg),
+		front:     cirque.New[T](threshold),
+		back:      cirque.New[T](threshold),
+		segments:  deque.New[segment](1),
+	}
+}
+
+// Len returns the number of items in the queue, whether in memory or spilled.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.front.Len() + q.spilled + q.back.Len()
+}
+
+// Spilled returns the number of items currently on disk.
+func (q *Queue[T]) Spilled() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.spilled
+}
+
+// Enqueue adds items to the back of the queue, spilling the back to disk if the items in
+// memory exceed the threshold. It only fails if the queue is closed: if spilling fails, the
+// items are enqueued all the same and stay in memory until a later Enqueue spills them, and
+// the error is logged, so that callers don't enqueue them twice by retrying.
+func (q *Queue[T]) Enqueue(items ...T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	// While nothing is spilled the front can take items directly.
+	if q.segments.Len() == 0 && q.back.Len() == 0 {
+		room := min(len(items), q.threshold-q.front.Len())
+		if room > 0 {
+			q.front.Enqueue(items[:room]...)
+			items = items[room:]
+		}
+	}
+	q.back.Enqueue(items...)
+
+	if q.front.Len()+q.back.Len() > q.threshold {
+		if err := q.spill(); err != nil {
+			log.Warningf("Failed to spill %d items, keeping them in memory: %v.", q.back.Len(), err)
+		}
+	}
+	return nil
+}
+
+// Write the back out as a new segment.
+// Must be called with mu held.
+func (q *Queue[T]) spill() error {
+	items := q.back.Snapshot()
+	records := make([][]byte, len(items))
+	for i, item := range items {
+		data, err := q.codec.Encode(item)
+		if err != nil {
+			return err
+		}
+		records[i] = data
+	}
+
+	s := segment{name: segmentName(q.nextID), count: len(items)}
+	if err := writeSegment(q.store, s.name, records); err != nil {
+		return err
+	}
+	q.nextID++
+
+	// Only drop the items from memory once they are safely on disk.
+	q.back.Discard(len(items))
+	q.segments.PushBack(s)
+	q.spilled += s.count
+
+	log.Debugf("Spilled %d items to %s.", s.count, s.name)
+	return nil
+}
+
+// Dequeue returns a maximum of n items from the front of the queue, reading spilled
+// segments back as needed.
+func (q *Queue[T]) Dequeue(n int) ([]T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []T
+	for len(result) < n {
+		if items := q.front.Dequeue(n - len(result)); len(items) > 0 {
+			result = append(result, items...)
+			continue
+		}
+		if err := q.refill(); err != nil {
+			return result, err
+		}
+		if q.front.Len() == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Move the next run of items into the empty front, from the oldest segment if there is one,
+// or from the back otherwise.
+// Must be called with mu held.
+func (q *Queue[T]) refill() error {
+	s, ok := q.segments.Front()
+	if !ok {
+		q.front, q.back = q.back, q.front
+		return nil
+	}
+
+	records, err := readSegment(q.store, s.name)
+	if err != nil {
+		return err
+	}
+	items := make([]T, len(records))
+	for i, r := range records {
+		if items[i], err = q.codec.Decode(r); err != nil {
+			return err
+		}
+	}
+
+	q.front.Enqueue(items...)
+	q.segments.PopFront()
+	q.spilled -= s.count
+
+	log.Debugf("Read back %d items from %s.", s.count, s.name)
+	return q.store.Remove(s.name)
+}
+
+// Close removes the segments of the queue. The queue can't be used afterwards.
+func (q *Queue[T]) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+
+	var errs []error
+	for {
+		s, ok := q.segments.PopFront()
+		if !ok {
+			break
+		}
+		errs = append(errs, q.store.Remove(s.name))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+package spill
+
+import (
+	"os"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+func TestSpill(t *testing.T) {
+	dir := t.TempDir()
+	q, err := New(dir, 4, codec.JSON[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	n := 50
+	for i := 0; i < n; i++ {
+		if err := q.Enqueue(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if q.Spilled() == 0 {
+		t.Fatal("Expected items to be spilled.")
+	}
+	if q.Len() != n {
+		t.Fatalf("Expected %d items, got %d.", n, q.Len())
+	}
+
+	// Interleave reads and writes while segments are being read back.
+	expected, appended := 0, false
+	for expected < n {
+		items, err := q.Dequeue(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			if item != expected {
+				t.Fatal("Items missing or reordered.")
+			}
+			expected++
+		}
+		if expected >= 20 && !appended {
+			if err := q.Enqueue(n, n+1); err != nil {
+				t.Fatal(err)
+			}
+			n += 2
+			appended = true
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 || q.Len() != 0 {
+		t.Fatal("Expected every segment to be read back and removed.")
+	}
+}
+
+func TestSpillFailure(t *testing.T) {
+	store := WithFaults(&memStore{blobs: make(map[string][]byte)})
+	q := NewWithStore(store, 2, codec.JSON[int]())
+	defer q.Close()
+
+	store.Fail(OpCreate, 1, nil)
+	if err := q.Enqueue(1, 2, 3); err != nil {
+		t.Fatalf("Expected the items to be kept in memory, got %v.", err)
+	}
+	if q.Len() != 3 || q.Spilled() != 0 {
+		t.Fatalf("Expected 3 items in memory, got %d spilled of %d.", q.Spilled(), q.Len())
+	}
+
+	// The next Enqueue spills what the failed one couldn't.
+	if err := q.Enqueue(4); err != nil {
+		t.Fatal(err)
+	}
+	if q.Spilled() == 0 {
+		t.Fatal("Expected items to be spilled.")
+	}
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || items[0] != 1 || items[3] != 4 {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
@@ -0,0 +1,74 @@
+package spill
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Store holds segments as named blobs. Implementations can keep them on local disk, like the
+// one returned by Dir, or in remote object storage, so that a backlog can outgrow the local disk.
+// A blob is only ever written once, by a single writer, before being read.
+type Store interface {
+	// Create returns a writer for a new blob with the given name. The blob must be complete
+	// and readable once the writer is closed without error.
+	Create(name string) (io.WriteCloser, error)
+	// Open returns a reader of the blob with the given name.
+	Open(name string) (io.ReadCloser, error)
+	// Remove deletes the blob with the given name.
+	Remove(name string) error
+	// List returns the names of all blobs, in lexical order.
+	List() ([]string, error)
+}
+
+// Sizer is implemented by stores that can tell the size of a blob, which Durable.Health uses
+// to report the space taken by a queue.
+type Sizer interface {
+	Size(name string) (int64, error)
+}
+
+// Dir returns a Store that keeps blobs as files in a local directory, creating it if it doesn't exist.
+func Dir(path string) (Store, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	return dirStore(path), nil
+}
+
+type dirStore string
+
+func (d dirStore) Create(name string) (io.WriteCloser, error) {
+	return os.Create(filepath.Join(string(d), name))
+}
+
+func (d dirStore) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), name))
+}
+
+func (d dirStore) Remove(name string) error {
+	return os.Remove(filepath.Join(string(d), name))
+}
+
+func (d dirStore) List() ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (d dirStore) Size(name string) (int64, error) {
+	info, err := os.Stat(filepath.Join(string(d), name))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
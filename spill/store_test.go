@@ -0,0 +1,103 @@
+package spill
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+// memStore is a Store like a client of remote object storage would implement.
+type memStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+type blobWriter struct {
+	bytes.Buffer
+	store *memStore
+	name  string
+}
+
+func (w *blobWriter) Close() error {
+	w.store.mu.Lock()
+	defer w.store.mu.Unlock()
+	w.store.blobs[w.name] = w.Bytes()
+	return nil
+}
+
+func (m *memStore) Create(name string) (io.WriteCloser, error) {
+	return &blobWriter{store: m, name: name}, nil
+}
+
+func (m *memStore) Open(name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	blob, ok := m.blobs[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(blob)), nil
+}
+
+func (m *memStore) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blobs, name)
+	return nil
+}
+
+func (m *memStore) List() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for name := range m.blobs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func TestCustomStore(t *testing.T) {
+	store := &memStore{blobs: make(map[string][]byte)}
+	q := NewWithStore(store, 2, codec.Gob[string]())
+
+	if err := q.Enqueue("a", "b", "c", "d", "e"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := store.List(); len(names) == 0 {
+		t.Fatal("Expected segments in the store.")
+	}
+
+	items, err := q.Dequeue(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, []string{"a", "b", "c", "d", "e"}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if names, _ := store.List(); len(names) != 0 {
+		t.Fatal("Expected segments to be removed once read back.")
+	}
+}
+
+func TestDirList(t *testing.T) {
+	store, err := Dir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b", "a"} {
+		w, err := store.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+	}
+	if names, _ := store.List(); !slices.Equal(names, []string{"a", "b"}) {
+		t.Fatalf("Unexpected blobs %v.", names)
+	}
+}
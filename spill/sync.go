@@ -0,0 +1,88 @@
+package spill
+
+import (
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	log "github.com/sirupsen/logrus"
+)
+
+// SyncPolicy decides when a Durable queue syncs enqueued items to stable storage,
+// trading throughput for the number of items that can be lost in a crash.
+// Syncing needs blob writers of the store to have a Sync method, like files do.
+// Writers without one are only flushed.
+type SyncPolicy int
+
+const (
+	// SyncNever leaves syncing to the store, like to the page cache of the operating system.
+	SyncNever SyncPolicy = iota
+	// SyncWrite syncs after every item.
+	SyncWrite
+	// SyncBatch syncs after every call to Enqueue.
+	SyncBatch
+	// SyncInterval syncs periodically, at the interval set with WithSyncInterval.
+	SyncInterval
+)
+
+// Default interval of SyncInterval.
+const defaultSyncInterval = time.Second
+
+// WithSync sets the sync policy of the queue. The default is SyncNever.
+func WithSync(policy SyncPolicy) Option {
+	return func(c *config) {
+		c.sync = policy
+	}
+}
+
+// WithSyncInterval makes the queue sync every interval, bounding the time that enqueued
+// items can stay unsynced.
+func WithSyncInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.sync = SyncInterval
+		if interval > 0 {
+			c.syncInterval = interval
+		}
+	}
+}
+
+// WithClock makes a Durable queue time SyncInterval with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// syncer is implemented by blob writers that can be synced to stable storage.
+type syncer interface {
+	Sync() error
+}
+
+// Flush writes every item enqueued so far to the store and syncs it, regardless of the sync policy.
+func (q *Durable[T]) Flush() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+	if q.active == nil {
+		return nil
+	}
+	return q.track(q.active.sync())
+}
+
+// Sync until the queue is closed.
+func (q *Durable[T]) syncEvery(interval time.Duration) {
+	for {
+		tick, timer := clock.After(q.clock, interval)
+		select {
+		case <-tick:
+			if err := q.Flush(); err != nil && err != ErrClosed {
+				log.Warningf("Failed to sync: %v.", err)
+			}
+		case <-q.done:
+			timer.Stop()
+			return
+		}
+	}
+}
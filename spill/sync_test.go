@@ -0,0 +1,92 @@
+package spill
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	"github.com/denis-ismailaj/cirque/codec"
+)
+
+// syncStore counts the syncs of its blob writers.
+type syncStore struct {
+	*memStore
+	syncs atomic.Int64
+}
+
+type syncWriter struct {
+	io.WriteCloser
+	store *syncStore
+}
+
+func (w syncWriter) Sync() error {
+	w.store.syncs.Add(1)
+	return nil
+}
+
+func (s *syncStore) Create(name string) (io.WriteCloser, error) {
+	w, err := s.memStore.Create(name)
+	return syncWriter{w, s}, err
+}
+
+func TestSyncPolicies(t *testing.T) {
+	tests := []struct {
+		policy SyncPolicy
+		syncs  int64
+	}{
+		{SyncNever, 0},
+		{SyncWrite, 3},
+		{SyncBatch, 1},
+	}
+	for _, test := range tests {
+		store := &syncStore{memStore: &memStore{blobs: make(map[string][]byte)}}
+		q, err := OpenDurable(store, codec.JSON[int](), WithSync(test.policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Enqueue(1, 2, 3); err != nil {
+			t.Fatal(err)
+		}
+		if store.syncs.Load() != test.syncs {
+			t.Fatalf("Expected %d syncs with policy %d, got %d.", test.syncs, test.policy, store.syncs.Load())
+		}
+
+		if err := q.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if store.syncs.Load() != test.syncs+1 {
+			t.Fatal("Expected Flush to sync.")
+		}
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	store := &syncStore{memStore: &memStore{blobs: make(map[string][]byte)}}
+	q, err := OpenDurable(store, codec.JSON[int](), WithSyncInterval(time.Minute), WithClock(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	if err := q.Enqueue(1); err != nil {
+		t.Fatal(err)
+	}
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if store.syncs.Load() != 0 {
+		t.Fatal("Expected the queue to wait for the interval before syncing.")
+	}
+
+	c.Advance(time.Minute)
+	deadline := time.Now().Add(time.Second)
+	for store.syncs.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the queue to sync periodically.")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
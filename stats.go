@@ -0,0 +1,67 @@
+package cirque
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds cumulative counters of a Cirque since its creation.
+type Stats struct {
+	Enqueued uint64        // Number of items written
+	Dequeued uint64        // Number of items read or discarded
+	Rejected uint64        // Number of items not admitted by an option, like duplicates
+	Expired  uint64        // Number of items skipped or swept because their TTL ran out
+	Evicted  uint64        // Number of items dropped by EvictOldest
+	Grows    uint64        // Number of times a writer found the queue full and had to grow it
+	GrowTime time.Duration // Total time writers spent growing, including waiting for readers
+}
+
+type stats struct {
+	enqueued atomic.Uint64
+	dequeued atomic.Uint64
+	rejected atomic.Uint64
+	expired  atomic.Uint64
+	evicted  atomic.Uint64
+	grows    atomic.Uint64
+	growTime atomic.Int64
+}
+
+// Stats returns the counters of the queue.
+// Each counter is read atomically, but they are not read together as a consistent whole.
+func (cq *Cirque[T]) Stats() Stats {
+	return Stats{
+		Enqueued: cq.stats.enqueued.Load(),
+		Dequeued: cq.stats.dequeued.Load(),
+		Rejected: cq.stats.rejected.Load(),
+		Expired:  cq.stats.expired.Load(),
+		Evicted:  cq.stats.evicted.Load(),
+		Grows:    cq.stats.grows.Load(),
+		GrowTime: time.Duration(cq.stats.growTime.Load()),
+	}
+}
+
+// Number of grows kept by a queue for Growths.
+const growthHistory = 16
+
+// Growth describes a time the queue grew.
+type Growth struct {
+	At   time.Time     // When the grow started
+	Took time.Duration // How long the grow took, including waiting for readers
+	Cap  int           // Capacity after the grow
+}
+
+// Growths returns the most recent times the queue grew, oldest first.
+func (cq *Cirque[T]) Growths() []Growth {
+	cq.readMu.Lock()
+	defer cq.readMu.Unlock()
+	return slices.Clone(cq.growths)
+}
+
+// Must be called with readMu held.
+func (cq *Cirque[T]) recordGrowth(start time.Time) {
+	if len(cq.growths) == growthHistory {
+		cq.growths = slices.Delete(cq.growths, 0, 1)
+	}
+	cq.growths = append(cq.growths, Growth{At: start, Took: time.Since(start), Cap: cq.cap})
+}
@@ -0,0 +1,33 @@
+package cirque
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	cq := New[int](2)
+
+	cq.Enqueue(1, 2, 3, 4)
+	cq.Dequeue(3)
+
+	s := cq.Stats()
+	if s.Enqueued != 4 || s.Dequeued != 3 {
+		t.Fatalf("Unexpected counters: %+v.", s)
+	}
+	if s.Grows == 0 || s.GrowTime <= 0 {
+		t.Fatalf("Expected the queue to have grown: %+v.", s)
+	}
+}
+
+func TestGrowths(t *testing.T) {
+	cq := New[int](2)
+	if len(cq.Growths()) != 0 {
+		t.Fatal("Expected no growths yet.")
+	}
+
+	for i := 0; i < 100; i++ {
+		cq.Enqueue(i)
+	}
+	growths := cq.Growths()
+	if len(growths) == 0 || growths[len(growths)-1].Cap != cq.Cap() {
+		t.Fatalf("Unexpected growths %+v.", growths)
+	}
+}
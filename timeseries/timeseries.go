@@ -0,0 +1,122 @@
+// Package timeseries provides a compact ring of timestamped samples with downsampled views.
+package timeseries
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a value observed at a point in time.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Bucket summarizes the samples that fall into a time interval.
+type Bucket struct {
+	Start time.Time // Start of the interval, a multiple of the bucket width
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// Avg returns the average value of the samples in the bucket.
+func (b Bucket) Avg() float64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return b.Sum / float64(b.Count)
+}
+
+// Ring keeps samples of the last retention duration, up to a fixed number of samples.
+// Samples are expected to be added in time order. Once the ring is allocated, adding
+// samples and downsampling into a large enough slice don't allocate.
+type Ring struct {
+	mu        sync.Mutex
+	samples   []Sample
+	start     int // Position of the oldest sample
+	len       int // Number of retained samples
+	retention time.Duration
+}
+
+// New creates a Ring that retains samples of the last retention duration, but no more than n of them.
+func New(n int, retention time.Duration) *Ring {
+	if n <= 0 || retention <= 0 {
+		return nil
+	}
+	return &Ring{samples: make([]Sample, n), retention: retention}
+}
+
+// Add records value v observed at t, dropping samples that are older than the retention
+// relative to t, or the oldest sample if the ring is full.
+func (r *Ring) Add(t time.Time, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(t)
+	if r.len == len(r.samples) {
+		r.drop()
+	}
+	r.samples[(r.start+r.len)%len(r.samples)] = Sample{Time: t, Value: v}
+	r.len++
+}
+
+// Must be called with mu held.
+func (r *Ring) expire(now time.Time) {
+	cutoff := now.Add(-r.retention)
+	for r.len > 0 && r.samples[r.start].Time.Before(cutoff) {
+		r.drop()
+	}
+}
+
+// Must be called with mu held.
+func (r *Ring) drop() {
+	r.start = (r.start + 1) % len(r.samples)
+	r.len--
+}
+
+// Len returns the number of retained samples.
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.len
+}
+
+// Samples appends the retained samples to dst, oldest first, and returns the extended slice.
+func (r *Ring) Samples(dst []Sample) []Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := 0; i < r.len; i++ {
+		dst = append(dst, r.samples[(r.start+i)%len(r.samples)])
+	}
+	return dst
+}
+
+// Downsample summarizes the retained samples into buckets of the given width, aligned to
+// multiples of width, and appends the non-empty ones to dst, oldest first.
+func (r *Ring) Downsample(width time.Duration, dst []Bucket) []Bucket {
+	if width <= 0 {
+		return dst
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	first := len(dst)
+	for i := 0; i < r.len; i++ {
+		s := r.samples[(r.start+i)%len(r.samples)]
+		start := s.Time.Truncate(width)
+
+		if len(dst) == first || !dst[len(dst)-1].Start.Equal(start) {
+			dst = append(dst, Bucket{Start: start, Min: s.Value, Max: s.Value})
+		}
+		b := &dst[len(dst)-1]
+		b.Count++
+		b.Sum += s.Value
+		b.Min = min(b.Min, s.Value)
+		b.Max = max(b.Max, s.Value)
+	}
+	return dst
+}
@@ -0,0 +1,58 @@
+package timeseries
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetention(t *testing.T) {
+	r := New(100, 10*time.Second)
+	base := time.Unix(1000, 0)
+
+	for i := 0; i < 20; i++ {
+		r.Add(base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+
+	// Only samples within 10 seconds of the latest one are retained.
+	samples := r.Samples(nil)
+	if len(samples) != 11 || samples[0].Value != 9 || samples[10].Value != 19 {
+		t.Fatalf("Unexpected samples %v.", samples)
+	}
+
+	// A full ring drops its oldest sample.
+	small := New(3, time.Hour)
+	for i := 0; i < 5; i++ {
+		small.Add(base.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	if samples := small.Samples(nil); len(samples) != 3 || samples[0].Value != 2 {
+		t.Fatalf("Unexpected samples %v.", samples)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	r := New(100, time.Minute)
+	base := time.Unix(1000, 0)
+
+	for i, v := range []float64{1, 5, 3, 10, 20} {
+		r.Add(base.Add(time.Duration(i)*2*time.Second), v)
+	}
+
+	buckets := r.Downsample(5*time.Second, make([]Bucket, 0, 4))
+	if len(buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %v.", buckets)
+	}
+	if b := buckets[0]; b.Count != 3 || b.Min != 1 || b.Max != 5 || b.Avg() != 3 {
+		t.Fatalf("Unexpected first bucket %+v.", b)
+	}
+	if b := buckets[1]; b.Count != 2 || b.Min != 10 || b.Max != 20 || b.Avg() != 15 {
+		t.Fatalf("Unexpected second bucket %+v.", b)
+	}
+
+	dst := make([]Bucket, 0, 4)
+	if allocs := testing.AllocsPerRun(100, func() {
+		r.Add(time.Unix(2000, 0), 1)
+		dst = r.Downsample(5*time.Second, dst[:0])
+	}); allocs != 0 {
+		t.Fatalf("Expected no allocations, got %v.", allocs)
+	}
+}
@@ -0,0 +1,216 @@
+package topic
+
+import (
+	"context"
+	"hash/maphash"
+	"slices"
+	"sync"
+
+	"github.com/denis-ismailaj/cirque"
+	log "github.com/sirupsen/logrus"
+)
+
+// Partitioned is a topic split into a fixed number of partitions, each backed by its own Cirque.
+// Every item goes to the partition of its key, so items with the same key keep their order.
+// Consumers join as members of a group, and every partition is assigned to exactly one member,
+// which is how ordering is kept on the consuming side too. Partitions are reassigned whenever
+// a member joins or leaves.
+type Partitioned[T any] struct {
+	mu         sync.Mutex
+	partitions []*cirque.Cirque[T]
+	key        func(T) string
+	seed       maphash.Seed
+	members    []*Member[T]  // In the order they joined
+	changed    chan struct{} // Closed and replaced whenever items are published or partitions reassigned
+	closed     bool
+}
+
+// NewPartitioned creates a Partitioned topic with n partitions, routing every item by the key
+// that key returns for it.
+func NewPartitioned[T any](n int, key func(T) string) *Partitioned[T] {
+	if n <= 0 {
+		return nil
+	}
+	p := &Partitioned[T]{
+		partitions: make([]*cirque.Cirque[T], n),
+		key:        key,
+		seed:       maphash.MakeSeed(),
+		changed:    make(chan struct{}),
+	}
+	for i := range p.partitions {
+		p.partitions[i] = cirque.New[T](1)
+	}
+	return p
+}
+
+// Partitions returns the number of partitions.
+func (p *Partitioned[T]) Partitions() int {
+	return len(p.partitions)
+}
+
+// Partition returns the partition that items with the given key go to.
+func (p *Partitioned[T]) Partition(key string) int {
+	return int(maphash.String(p.seed, key) % uint64(len(p.partitions)))
+}
+
+// Must be called with mu held.
+func (p *Partitioned[T]) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// Publish routes every item to the partition of its key. Items of a single call that share
+// a partition are enqueued together and in order.
+func (p *Partitioned[T]) Publish(items ...T) error {
+	batches := make([][]T, len(p.partitions))
+	for _, item := range items {
+		i := p.Partition(p.key(item))
+		batches[i] = append(batches[i], item)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+	for i, batch := range batches {
+		if len(batch) > 0 {
+			p.partitions[i].Enqueue(batch...)
+		}
+	}
+	p.notify()
+	return nil
+}
+
+// Close closes the topic for publishing. Members can still dequeue what was published before,
+// after which DequeueWait returns ErrClosed.
+func (p *Partitioned[T]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+	p.closed = true
+	for _, q := range p.partitions {
+		q.Close()
+	}
+	p.notify()
+}
+
+// Join adds a new member to the consumer group, and reassigns partitions among all members.
+func (p *Partitioned[T]) Join() *Member[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := &Member[T]{p: p}
+	p.members = append(p.members, m)
+	p.rebalance()
+	return m
+}
+
+// Assign partition i to member i modulo the number of members, so that every member gets
+// an even share. Members beyond the number of partitions get none.
+// Must be called with mu held.
+func (p *Partitioned[T]) rebalance() {
+	assigned := make([][]int, len(p.members))
+	for i := range p.partitions {
+		if len(p.members) > 0 {
+			assigned[i%len(p.members)] = append(assigned[i%len(p.members)], i)
+		}
+	}
+	for i, m := range p.members {
+		m.assigned = assigned[i]
+		queues := make([]*cirque.Cirque[T], len(m.assigned))
+		for j, partition := range m.assigned {
+			queues[j] = p.partitions[partition]
+		}
+		m.mux = cirque.NewMux(queues...)
+	}
+	p.notify()
+
+	log.Debugf("Assigned %d partitions to %d members.", len(p.partitions), len(p.members))
+}
+
+// Member is a consumer that gets items only from the partitions assigned to it.
+type Member[T any] struct {
+	p        *Partitioned[T]
+	assigned []int          // Guarded by the mutex of p
+	mux      *cirque.Mux[T] // Over the assigned partitions, nil if there are none
+	left     bool           // Guarded by the mutex of p
+}
+
+// Partitions returns the partitions currently assigned to the member.
+func (m *Member[T]) Partitions() []int {
+	m.p.mu.Lock()
+	defer m.p.mu.Unlock()
+	return slices.Clone(m.assigned)
+}
+
+// Dequeue returns a maximum of n items from the partitions assigned to the member, in
+// round-robin, without waiting.
+func (m *Member[T]) Dequeue(n int) []T {
+	m.p.mu.Lock()
+	defer m.p.mu.Unlock()
+
+	// Holding the lock keeps partitions from being reassigned in the middle of a dequeue.
+	if m.mux == nil {
+		return nil
+	}
+	return m.mux.Dequeue(n)
+}
+
+// DequeueWait returns a maximum of n items from the partitions assigned to the member, waiting
+// until there is at least one. It returns ErrClosed once the topic is closed and the assigned
+// partitions are empty, or if the member left, and the context's error if ctx is done first.
+func (m *Member[T]) DequeueWait(ctx context.Context, n int) ([]T, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	p := m.p
+	for {
+		p.mu.Lock()
+		if m.left {
+			p.mu.Unlock()
+			return nil, ErrClosed
+		}
+		var items []T
+		if m.mux != nil {
+			items = m.mux.Dequeue(n)
+		}
+		if len(items) > 0 {
+			p.mu.Unlock()
+			return items, nil
+		}
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrClosed
+		}
+		changed := p.changed
+		p.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Leave removes the member from the group, and reassigns its partitions to the remaining members.
+// Items it hadn't dequeued stay in their partitions for the next member.
+func (m *Member[T]) Leave() {
+	p := m.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if m.left {
+		return
+	}
+	m.left = true
+	m.assigned, m.mux = nil, nil
+	p.members = slices.DeleteFunc(p.members, func(other *Member[T]) bool { return other == m })
+	p.rebalance()
+}
@@ -0,0 +1,103 @@
+package topic
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+type event struct {
+	key string
+	seq int
+}
+
+func TestPartitionedOrder(t *testing.T) {
+	p := NewPartitioned(4, func(e event) string { return e.key })
+	members := []*Member[event]{p.Join(), p.Join()}
+
+	var published []event
+	for i := 0; i < 100; i++ {
+		published = append(published, event{fmt.Sprint(i % 7), i})
+	}
+	if err := p.Publish(published...); err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	// Every key must be consumed by one member only, in order.
+	owner := make(map[string]int)
+	last := make(map[string]int)
+	total := 0
+	for i, m := range members {
+		for {
+			items, err := m.DequeueWait(context.Background(), 10)
+			if err == ErrClosed {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range items {
+				if o, ok := owner[e.key]; ok && o != i {
+					t.Fatalf("Key %s consumed by two members.", e.key)
+				}
+				owner[e.key] = i
+				if s, ok := last[e.key]; ok && s > e.seq {
+					t.Fatalf("Items of key %s reordered.", e.key)
+				}
+				last[e.key] = e.seq
+				total++
+			}
+		}
+	}
+	if total != len(published) {
+		t.Fatalf("Expected %d items, got %d.", len(published), total)
+	}
+}
+
+func TestPartitionedRebalance(t *testing.T) {
+	p := NewPartitioned(3, func(s string) string { return s })
+	a := p.Join()
+	if !slices.Equal(a.Partitions(), []int{0, 1, 2}) {
+		t.Fatalf("Unexpected partitions %v.", a.Partitions())
+	}
+
+	b := p.Join()
+	if !slices.Equal(a.Partitions(), []int{0, 2}) || !slices.Equal(b.Partitions(), []int{1}) {
+		t.Fatalf("Unexpected partitions %v and %v.", a.Partitions(), b.Partitions())
+	}
+
+	// Items left by a member go to the next owner of their partition.
+	if err := p.Publish("x", "y", "z"); err != nil {
+		t.Fatal(err)
+	}
+	a.Leave()
+	if len(b.Partitions()) != 3 || len(b.Dequeue(10)) != 3 {
+		t.Fatal("Expected the remaining member to take over every partition.")
+	}
+	if _, err := a.DequeueWait(context.Background(), 1); err != ErrClosed {
+		t.Fatal("Expected a member that left to get ErrClosed.")
+	}
+}
+
+func TestPartitionedWait(t *testing.T) {
+	p := NewPartitioned(2, func(s string) string { return s })
+	m := p.Join()
+
+	done := make(chan []string)
+	go func() {
+		items, _ := m.DequeueWait(context.Background(), 1)
+		done <- items
+	}()
+	p.Publish("a")
+	if items := <-done; len(items) != 1 || items[0] != "a" {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.DequeueWait(ctx, 1); err != context.Canceled {
+		t.Fatal("Expected the context's error.")
+	}
+}
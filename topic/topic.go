@@ -0,0 +1,182 @@
+// Package topic provides a publish/subscribe topic where every subscriber gets its own Cirque.
+package topic
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/denis-ismailaj/cirque"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrClosed is returned when publishing to a closed topic.
+var ErrClosed = errors.New("topic: topic closed")
+
+// Policy decides what happens when a subscriber falls behind by more than its buffer size.
+type Policy int
+
+const (
+	// Grow lets the subscriber's queue grow without bound.
+	Grow Policy = iota
+	// DropOldest discards the oldest items of the subscriber to make room for new ones.
+	DropOldest
+	// Disconnect closes the subscription. The subscriber can still read what it had buffered.
+	Disconnect
+)
+
+// Topic fans out every published item to all of its subscribers.
+type Topic[T any] struct {
+	mu     sync.Mutex
+	subs   map[*Subscription[T]]struct{}
+	policy Policy
+	closed bool
+}
+
+// New creates a Topic that treats slow subscribers according to policy.
+func New[T any](policy Policy) *Topic[T] {
+	return &Topic[T]{
+		subs:   make(map[*Subscription[T]]struct{}),
+		policy: policy,
+	}
+}
+
+// Subscribe creates a subscription that sees every item published from now on.
+// buffer is the initial size of its queue, and how far it may fall behind before the
+// topic's policy applies.
+func (t *Topic[T]) Subscribe(buffer int) *Subscription[T] {
+	return t.SubscribeFunc(buffer, nil)
+}
+
+// SubscribeFunc is like Subscribe, but the subscription only gets the items for which match
+// returns true. A nil match gets every item.
+func (t *Topic[T]) SubscribeFunc(buffer int, match func(T) bool) *Subscription[T] {
+	if buffer <= 0 {
+		return nil
+	}
+
+	s := &Subscription[T]{
+		topic:  t,
+		queue:  cirque.New[T](buffer + 1), // Cirque always keeps one slot empty
+		buffer: buffer,
+		match:  match,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		s.queue.Close()
+		return s
+	}
+	t.subs[s] = struct{}{}
+	log.Debugf("Subscribed, %d subscribers total.", len(t.subs))
+	return s
+}
+
+// Publish delivers items to every subscriber. Items of concurrent calls reach all
+// subscribers in the same order.
+func (t *Topic[T]) Publish(items ...T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
+	}
+	for s := range t.subs {
+		t.deliver(s, items)
+	}
+	return nil
+}
+
+// Must be called with mu held.
+func (t *Topic[T]) deliver(s *Subscription[T], items []T) {
+	if s.match != nil {
+		items = slices.DeleteFunc(slices.Clone(items), func(item T) bool {
+			return !s.match(item)
+		})
+		if len(items) == 0 {
+			return
+		}
+	}
+
+	if over := s.queue.Len() + len(items) - s.buffer; over > 0 {
+		switch t.policy {
+		case DropOldest:
+			// A batch larger than the whole buffer only keeps its newest items.
+			if len(items) > s.buffer {
+				s.dropped.Add(uint64(len(items) - s.buffer))
+				items = items[len(items)-s.buffer:]
+			}
+			if over := s.queue.Len() + len(items) - s.buffer; over > 0 {
+				s.dropped.Add(uint64(s.queue.Discard(over)))
+			}
+		case Disconnect:
+			log.Debugf("Disconnecting subscriber that fell behind by %d items.", over)
+			t.remove(s)
+			return
+		}
+	}
+	s.queue.Enqueue(items...)
+}
+
+// Must be called with mu held.
+func (t *Topic[T]) remove(s *Subscription[T]) {
+	if _, ok := t.subs[s]; !ok {
+		return
+	}
+	delete(t.subs, s)
+	s.queue.Close()
+}
+
+// Close closes every subscription. Subscribers can still read what they had buffered.
+// Close is idempotent.
+func (t *Topic[T]) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	for s := range t.subs {
+		t.remove(s)
+	}
+}
+
+// Subscription is a subscriber's queue of published items.
+type Subscription[T any] struct {
+	topic   *Topic[T]
+	queue   *cirque.Cirque[T]
+	buffer  int
+	match   func(T) bool // Nil if the subscriber gets every item
+	dropped atomic.Uint64
+}
+
+// Dequeue returns a maximum of n items, without waiting.
+func (s *Subscription[T]) Dequeue(n int) []T {
+	return s.queue.Dequeue(n)
+}
+
+// Items returns an iterator over published items, blocking while there are none. The iteration
+// ends when the subscription is closed and every buffered item was read, or when ctx is done.
+func (s *Subscription[T]) Items(ctx context.Context) iter.Seq[T] {
+	return s.queue.Drain(ctx)
+}
+
+// Len returns the number of buffered items.
+func (s *Subscription[T]) Len() int {
+	return s.queue.Len()
+}
+
+// Dropped returns the number of items discarded because the subscriber fell behind.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes from the topic.
+func (s *Subscription[T]) Close() {
+	s.topic.mu.Lock()
+	defer s.topic.mu.Unlock()
+	s.topic.remove(s)
+}
@@ -0,0 +1,80 @@
+package topic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	tp := New[int](Grow)
+	a := tp.Subscribe(2)
+	b := tp.Subscribe(2)
+
+	if err := tp.Publish(1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	tp.Close()
+
+	for _, s := range []*Subscription[int]{a, b} {
+		items := slices.Collect(s.Items(context.Background()))
+		if !slices.Equal(items, []int{1, 2, 3}) {
+			t.Fatal("Items missing or reordered.")
+		}
+	}
+	if err := tp.Publish(4); !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected publish after close to fail.")
+	}
+}
+
+func TestDropOldest(t *testing.T) {
+	tp := New[int](DropOldest)
+	s := tp.Subscribe(3)
+
+	_ = tp.Publish(1, 2)
+	_ = tp.Publish(3, 4)
+	_ = tp.Publish(5, 6, 7, 8)
+
+	if items := s.Dequeue(10); !slices.Equal(items, []int{6, 7, 8}) {
+		t.Fatalf("Expected only the newest items, got %v.", items)
+	}
+	if s.Dropped() != 5 {
+		t.Fatalf("Expected 5 dropped items, got %d.", s.Dropped())
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	tp := New[int](Disconnect)
+	slow := tp.Subscribe(1)
+	fast := tp.Subscribe(1)
+
+	_ = tp.Publish(1)
+	fast.Dequeue(1)
+	_ = tp.Publish(2)
+
+	// The slow subscriber keeps what it had buffered, but gets nothing more.
+	items := slices.Collect(slow.Items(context.Background()))
+	if !slices.Equal(items, []int{1}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if items := fast.Dequeue(10); !slices.Equal(items, []int{2}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
+
+func TestSubscribeFunc(t *testing.T) {
+	tp := New[int](Disconnect)
+	even := tp.SubscribeFunc(2, func(i int) bool { return i%2 == 0 })
+	all := tp.Subscribe(8)
+
+	_ = tp.Publish(1, 2, 3, 4, 5)
+
+	// Filtered out items don't count against the buffer.
+	if items := even.Dequeue(10); !slices.Equal(items, []int{2, 4}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if all.Len() != 5 {
+		t.Fatal("Expected unfiltered subscribers to get every item.")
+	}
+}
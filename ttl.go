@@ -0,0 +1,115 @@
+package cirque
+
+import (
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+	log "github.com/sirupsen/logrus"
+)
+
+// expiring wraps items enqueued with a TTL, or on a queue that records enqueue times, in the ring.
+// Other items are stored as they are.
+type expiring[T any] struct {
+	item     T
+	expires  time.Time // Zero if the item doesn't expire
+	enqueued time.Time // Zero unless the queue records enqueue times
+}
+
+// Return the item stored in a ring value, and when it expires or zero if it doesn't.
+func unbox[T any](v any) (T, time.Time) {
+	if e, ok := v.(expiring[T]); ok {
+		return e.item, e.expires
+	}
+	// Nil items of interface types are stored as nil, which only converts back without checking.
+	item, _ := v.(T)
+	return item, time.Time{}
+}
+
+// OnExpire makes the queue call f with every item that expires before being dequeued.
+// f is called from the goroutine that found the item expired, like a reader or the sweeper.
+func OnExpire[T any](f func(T)) Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.onExpire = f
+	}
+}
+
+// WithSweep makes the queue remove expired items from its front every interval, until it is closed,
+// so that stale items don't hold on to memory while nobody reads. Expired items further back are
+// only removed once the items in front of them are gone.
+func WithSweep[T any](interval time.Duration) Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.sweepInterval = interval
+	}
+}
+
+// WithClock makes the queue tell time with c instead of the real clock, for expiry, enqueue times,
+// dedup windows and bursts, so that tests can drive them with a fake clock.
+// Measurements of how long operations took, like grows, always use the real clock.
+func WithClock[T any](c clock.Clock) Option[T] {
+	return func(cq *Cirque[T]) {
+		cq.clock = c
+	}
+}
+
+// EnqueueWithTTL adds item to the queue, to be skipped by readers once ttl has passed.
+func (cq *Cirque[T]) EnqueueWithTTL(item T, ttl time.Duration) {
+	cq.expiring.Store(true)
+	expires := cq.clock.Now().Add(ttl)
+
+	if cq.bound.limit > 0 {
+		cq.enqueueBounded([]T{item}, nil, expires)
+		return
+	}
+	if _, err := cq.enqueue([]T{item}, nil, expires); err != nil {
+		cq.reject([]T{item}, err)
+	}
+}
+
+// Current time, or zero if there are no expiring items so that readers don't need to look it up.
+func (cq *Cirque[T]) now() time.Time {
+	if !cq.expiring.Load() {
+		return time.Time{}
+	}
+	return cq.clock.Now()
+}
+
+func (cq *Cirque[T]) reportExpired(expired []T) {
+	if len(expired) == 0 {
+		return
+	}
+	log.Debugf("Skipped %d expired items.", len(expired))
+	if cq.onExpire != nil {
+		for _, item := range expired {
+			cq.onExpire(item)
+		}
+	}
+}
+
+// Sweep removes expired items from the front of the queue, up to the first item that hasn't
+// expired, and returns how many were removed.
+func (cq *Cirque[T]) Sweep() int {
+	if !cq.expiring.Load() {
+		return 0
+	}
+
+	now := cq.clock.Now()
+	expired := cq.dropFront(func(_ T, expires time.Time) bool {
+		return !expires.IsZero() && !now.Before(expires)
+	})
+	cq.stats.expired.Add(uint64(len(expired)))
+	cq.reportExpired(expired)
+	return len(expired)
+}
+
+func (cq *Cirque[T]) sweepEvery(interval time.Duration) {
+	for {
+		tick, timer := clock.After(cq.clock, interval)
+		select {
+		case <-tick:
+			cq.Sweep()
+		case <-cq.done:
+			timer.Stop()
+			return
+		}
+	}
+}
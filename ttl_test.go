@@ -0,0 +1,83 @@
+package cirque
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestTTL(t *testing.T) {
+	var expired []int
+	cq := New(4, OnExpire(func(i int) { expired = append(expired, i) }))
+
+	cq.Enqueue(1)
+	cq.EnqueueWithTTL(2, time.Millisecond)
+	cq.EnqueueWithTTL(3, time.Hour)
+	cq.Enqueue(4)
+	time.Sleep(2 * time.Millisecond)
+
+	if items := cq.Snapshot(); !slices.Equal(items, []int{1, 3, 4}) {
+		t.Fatalf("Expected expired items to be left out, got %v.", items)
+	}
+
+	// Expired items don't count towards n, and keep their sequence numbers.
+	items := cq.DequeueSeq(2)
+	if len(items) != 2 || items[1] != (Sequenced[int]{Seq: 3, Item: 3}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+	if !slices.Equal(expired, []int{2}) || cq.Stats().Expired != 1 {
+		t.Fatalf("Expected the expired item to be reported, got %v.", expired)
+	}
+	if cq.Len() != 1 {
+		t.Fatalf("Expected 1 item left, got %d.", cq.Len())
+	}
+}
+
+func TestSweep(t *testing.T) {
+	cq := New(4, WithSweep[int](time.Millisecond), WithBound[int](3))
+	defer cq.Close()
+
+	cq.EnqueueWithTTL(1, time.Millisecond)
+	cq.EnqueueWithTTL(2, time.Millisecond)
+	cq.EnqueueWithTTL(3, time.Hour)
+
+	deadline := time.Now().Add(time.Second)
+	for cq.Len() > 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the sweeper to remove expired items.")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Swept items free their room in a bounded queue.
+	if err := cq.TryEnqueue(4, 5); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTTLClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	cq := New(4, WithClock[int](c), WithSweep[int](time.Minute))
+	defer cq.Close()
+
+	cq.EnqueueWithTTL(1, time.Second)
+	cq.EnqueueWithTTL(2, time.Hour)
+
+	// The sweeper runs on the fake clock too.
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(time.Minute)
+	// The sweeper schedules its next run once done with this one.
+	for c.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if cq.Len() != 1 {
+		t.Fatalf("Expected the sweeper to remove the expired item, got %d items.", cq.Len())
+	}
+	if items := cq.Dequeue(10); !slices.Equal(items, []int{2}) {
+		t.Fatalf("Unexpected items %v.", items)
+	}
+}
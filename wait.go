@@ -0,0 +1,68 @@
+package cirque
+
+import (
+	"container/list"
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned by writes to a closed queue, and by DequeueWait once the queue is closed and empty.
+var ErrClosed = errors.New("cirque: queue closed")
+
+// DequeueWait returns a maximum of n items from the queue, waiting until there is at least one.
+// It returns ErrClosed once the queue is closed and empty, or the context's error if ctx is done first.
+//
+// Goroutines waiting at the same time are served in the order they started waiting,
+// so none of them can be starved by the others.
+func (cq *Cirque[T]) DequeueWait(ctx context.Context, n int) ([]T, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// Get in line, and wait for the turn unless first.
+	turn := make(chan struct{})
+	cq.waitMu.Lock()
+	e := cq.waiters.PushBack(turn)
+	first := cq.waiters.Len() == 1
+	cq.waitMu.Unlock()
+	defer cq.leave(e)
+
+	if !first {
+		select {
+		case <-turn:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Only the first in line waits for items, so the others can't snatch them.
+	for {
+		if items := cq.Dequeue(n); len(items) > 0 {
+			return items, nil
+		}
+
+		reach(Wait)
+		select {
+		case <-cq.ready:
+		case <-cq.done:
+			// Items may have been enqueued right before closing.
+			if cq.Len() == 0 {
+				return nil, ErrClosed
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Get out of line, handing the turn to the next waiter if this one had it.
+func (cq *Cirque[T]) leave(e *list.Element) {
+	cq.waitMu.Lock()
+	defer cq.waitMu.Unlock()
+
+	wasFirst := cq.waiters.Front() == e
+	cq.waiters.Remove(e)
+	if next := cq.waiters.Front(); wasFirst && next != nil {
+		close(next.Value.(chan struct{}))
+	}
+}
@@ -0,0 +1,69 @@
+package cirque
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDequeueWaitFIFO(t *testing.T) {
+	cq := New[int](4)
+
+	// Line up waiters one by one.
+	results := make([]chan int, 3)
+	for i := range results {
+		results[i] = make(chan int, 1)
+		go func(c chan int) {
+			items, err := cq.DequeueWait(context.Background(), 1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			c <- items[0]
+		}(results[i])
+
+		for cq.waitersLen() < i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Waiters get items in the order they started waiting.
+	for i, c := range results {
+		cq.Enqueue(i)
+		if item := <-c; item != i {
+			t.Fatalf("Waiter %d got item %d.", i, item)
+		}
+	}
+}
+
+func TestDequeueWaitCancelledInLine(t *testing.T) {
+	cq := New[int](4)
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := cq.DequeueWait(context.Background(), 1)
+		first <- err
+	}()
+	for cq.waitersLen() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A waiter that gives up while in line doesn't hold up the others.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cq.DequeueWait(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected the second waiter to time out.")
+	}
+
+	cq.Close()
+	if err := <-first; !errors.Is(err, ErrClosed) {
+		t.Fatal("Expected the first waiter to see the queue closed.")
+	}
+}
+
+func (cq *Cirque[T]) waitersLen() int {
+	cq.waitMu.Lock()
+	defer cq.waitMu.Unlock()
+	return cq.waiters.Len()
+}
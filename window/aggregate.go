@@ -0,0 +1,163 @@
+package window
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+)
+
+// Number is the set of numeric types that can be summed.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Result is the aggregate of the items of a single time window.
+type Result[A any] struct {
+	Start time.Time // Start of the window, inclusive
+	End   time.Time // End of the window, exclusive
+	Value A
+}
+
+// Aggregator folds timestamped items into tumbling or sliding time windows, and emits the
+// aggregate of every window once the watermark passes its end.
+//
+// The watermark trails the latest item time seen by the allowed lateness. Items that arrive
+// for windows that have already been emitted are dropped and counted as late.
+type Aggregator[T, A any] struct {
+	mu        sync.Mutex
+	size      time.Duration
+	slide     time.Duration
+	lateness  time.Duration
+	timeOf    func(T) time.Time
+	init      func() A
+	fold      func(A, T) A
+	open      map[time.Time]A // Aggregates of windows not emitted yet, by start time
+	latest    time.Time       // Latest item time seen
+	watermark time.Time       // Windows ending at or before this time are complete
+	late      uint64
+}
+
+// NewTumbling creates an Aggregator over consecutive, non-overlapping windows of the given size.
+// Every window starts as init() and every item is added to it with fold.
+func NewTumbling[T, A any](size, lateness time.Duration, timeOf func(T) time.Time, init func() A, fold func(A, T) A) *Aggregator[T, A] {
+	return NewSliding(size, size, lateness, timeOf, init, fold)
+}
+
+// NewSliding creates an Aggregator over windows of the given size that start every slide,
+// so that every item belongs to size/slide windows.
+// Every window starts as init() and every item is added to it with fold.
+func NewSliding[T, A any](size, slide, lateness time.Duration, timeOf func(T) time.Time, init func() A, fold func(A, T) A) *Aggregator[T, A] {
+	if size <= 0 || slide <= 0 || slide > size {
+		return nil
+	}
+	return &Aggregator[T, A]{
+		size:     size,
+		slide:    slide,
+		lateness: lateness,
+		timeOf:   timeOf,
+		init:     init,
+		fold:     fold,
+		open:     make(map[time.Time]A),
+	}
+}
+
+// Add folds item into every window it belongs to, and returns the windows completed by the
+// watermark advancing, ordered by start time.
+func (a *Aggregator[T, A]) Add(item T) []Result[A] {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	t := a.timeOf(item)
+
+	// Windows are aligned to multiples of slide, so the last one containing t starts at
+	// t truncated to slide, and earlier ones every slide before that.
+	for start := t.Truncate(a.slide); start.Add(a.size).After(t); start = start.Add(-a.slide) {
+		if !start.Add(a.size).After(a.watermark) {
+			// Already emitted, so it's too late for this window.
+			a.late++
+			continue
+		}
+		acc, ok := a.open[start]
+		if !ok {
+			acc = a.init()
+		}
+		a.open[start] = a.fold(acc, item)
+	}
+
+	if t.After(a.latest) {
+		a.latest = t
+		if wm := t.Add(-a.lateness); wm.After(a.watermark) {
+			a.watermark = wm
+		}
+	}
+	return a.emit(func(end time.Time) bool { return !end.After(a.watermark) })
+}
+
+// Flush returns all windows not emitted yet, regardless of the watermark, ordered by start time.
+// It is meant for the end of a stream.
+func (a *Aggregator[T, A]) Flush() []Result[A] {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	results := a.emit(func(time.Time) bool { return true })
+	for _, r := range results {
+		if r.End.After(a.watermark) {
+			a.watermark = r.End
+		}
+	}
+	return results
+}
+
+// Remove and return the open windows whose end satisfies done, ordered by start time.
+// Must be called with mu held.
+func (a *Aggregator[T, A]) emit(done func(end time.Time) bool) []Result[A] {
+	var results []Result[A]
+	for start, acc := range a.open {
+		if end := start.Add(a.size); done(end) {
+			results = append(results, Result[A]{Start: start, End: end, Value: acc})
+			delete(a.open, start)
+		}
+	}
+	slices.SortFunc(results, func(x, y Result[A]) int { return x.Start.Compare(y.Start) })
+	return results
+}
+
+// Late returns the number of times an item arrived for a window that had already been emitted.
+func (a *Aggregator[T, A]) Late() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.late
+}
+
+// Run consumes items from q as they arrive and passes every completed window to emit, until
+// q is closed and empty or ctx is done. Remaining windows are flushed when q is closed.
+func (a *Aggregator[T, A]) Run(ctx context.Context, q *cirque.Cirque[T], emit func(Result[A])) {
+	for item := range q.Drain(ctx) {
+		for _, r := range a.Add(item) {
+			emit(r)
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	for _, r := range a.Flush() {
+		emit(r)
+	}
+}
+
+// Count is a fold function that counts items.
+func Count[T any](n int, _ T) int {
+	return n + 1
+}
+
+// SumOf returns a fold function that sums the values extracted from items by value.
+func SumOf[T any, N Number](value func(T) N) func(N, T) N {
+	return func(sum N, item T) N {
+		return sum + value(item)
+	}
+}
@@ -0,0 +1,72 @@
+package window
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque"
+)
+
+type event struct {
+	at    time.Time
+	value int
+}
+
+func eventTime(e event) time.Time { return e.at }
+
+func zero() int { return 0 }
+
+func TestTumbling(t *testing.T) {
+	base := time.Unix(1000, 0)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+
+	a := NewTumbling(10*time.Second, 2*time.Second, eventTime, zero, SumOf(func(e event) int { return e.value }))
+
+	var results []Result[int]
+	for _, e := range []event{{at(1), 1}, {at(5), 2}, {at(11), 3}, {at(9), 4}, {at(13), 5}, {at(3), 6}, {at(25), 7}} {
+		results = append(results, a.Add(e)...)
+	}
+	results = append(results, a.Flush()...)
+
+	// The item at 9 is still on time because of the allowed lateness, the one at 3 is not.
+	expected := []int{1 + 2 + 4, 3 + 5, 7}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d windows, got %v.", len(expected), results)
+	}
+	for i, r := range results {
+		if r.Value != expected[i] || !r.Start.Equal(at(10*i)) {
+			t.Fatalf("Unexpected window %d: %+v.", i, r)
+		}
+	}
+	if a.Late() != 1 {
+		t.Fatalf("Expected 1 late item, got %d.", a.Late())
+	}
+}
+
+func TestSlidingRun(t *testing.T) {
+	base := time.Unix(1000, 0)
+	q := cirque.New[event](4)
+	for s := 0; s < 6; s++ {
+		q.Enqueue(event{at: base.Add(time.Duration(s) * time.Second)})
+	}
+	q.Close()
+
+	a := NewSliding(4*time.Second, 2*time.Second, 0, eventTime, zero, Count[event])
+
+	var counts []int
+	a.Run(context.Background(), q, func(r Result[int]) {
+		counts = append(counts, r.Value)
+	})
+
+	// Windows start every 2 seconds, from the one that covers the first item.
+	expected := []int{2, 4, 4, 2}
+	if len(counts) != len(expected) {
+		t.Fatalf("Expected %v, got %v.", expected, counts)
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v.", expected, counts)
+		}
+	}
+}
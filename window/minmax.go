@@ -0,0 +1,94 @@
+// Package window provides structures that track properties of the last N items of a stream.
+package window
+
+import (
+	"cmp"
+	"sync"
+
+	"github.com/denis-ismailaj/cirque/deque"
+)
+
+// MinMax tracks the minimum and maximum of the last n pushed items.
+//
+// It keeps two monotonic deques of candidates: an item stops being a candidate for the
+// minimum as soon as a smaller or equal item is pushed after it, and vice versa for the
+// maximum. Every item enters and leaves each deque at most once, so Push is O(1) amortized
+// and Min and Max are O(1).
+type MinMax[T cmp.Ordered] struct {
+	mu    sync.Mutex
+	n     uint64
+	count uint64                  // Number of items pushed so far
+	mins  *deque.Deque[*entry[T]] // Candidates for the minimum, increasing from front to back
+	maxs  *deque.Deque[*entry[T]] // Candidates for the maximum, decreasing from front to back
+}
+
+type entry[T any] struct {
+	index uint64
+	value T
+}
+
+// NewMinMax creates a MinMax over a window of the last n items.
+func NewMinMax[T cmp.Ordered](n int) *MinMax[T] {
+	if n <= 0 {
+		return nil
+	}
+	return &MinMax[T]{
+		n:    uint64(n),
+		mins: deque.New[*entry[T]](n),
+		maxs: deque.New[*entry[T]](n),
+	}
+}
+
+// Push adds v to the window, evicting the oldest item if the window is full.
+func (w *MinMax[T]) Push(v T) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	e := &entry[T]{index: w.count, value: v}
+	w.count++
+
+	push(w.mins, e, w.count, w.n, func(back T) bool { return back >= v })
+	push(w.maxs, e, w.count, w.n, func(back T) bool { return back <= v })
+}
+
+// Append e to a monotonic deque after dropping candidates outside the window from the front
+// and candidates that e makes obsolete from the back.
+func push[T cmp.Ordered](d *deque.Deque[*entry[T]], e *entry[T], count, n uint64, obsolete func(T) bool) {
+	for front, ok := d.Front(); ok && front.index+n < count; front, ok = d.Front() {
+		d.PopFront()
+	}
+	for back, ok := d.Back(); ok && obsolete(back.value); back, ok = d.Back() {
+		d.PopBack()
+	}
+	d.PushBack(e)
+}
+
+// Min returns the minimum of the window, or reports false if nothing has been pushed yet.
+func (w *MinMax[T]) Min() (T, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return front(w.mins)
+}
+
+// Max returns the maximum of the window, or reports false if nothing has been pushed yet.
+func (w *MinMax[T]) Max() (T, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return front(w.maxs)
+}
+
+func front[T any](d *deque.Deque[*entry[T]]) (T, bool) {
+	e, ok := d.Front()
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Len returns the number of items currently in the window.
+func (w *MinMax[T]) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return int(min(w.count, w.n))
+}
@@ -0,0 +1,36 @@
+package window
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestMinMax(t *testing.T) {
+	n := 7
+	w := NewMinMax[int](n)
+
+	if _, ok := w.Min(); ok {
+		t.Fatal("Expected no minimum on an empty window.")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	var pushed []int
+	for i := 0; i < 1000; i++ {
+		v := rng.Intn(100)
+		w.Push(v)
+		pushed = append(pushed, v)
+
+		// Compare with a brute force scan of the window.
+		last := pushed[max(0, len(pushed)-n):]
+		if m, _ := w.Min(); m != slices.Min(last) {
+			t.Fatalf("Expected min %d, got %d.", slices.Min(last), m)
+		}
+		if m, _ := w.Max(); m != slices.Max(last) {
+			t.Fatalf("Expected max %d, got %d.", slices.Max(last), m)
+		}
+		if w.Len() != len(last) {
+			t.Fatalf("Expected length %d, got %d.", len(last), w.Len())
+		}
+	}
+}
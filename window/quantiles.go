@@ -0,0 +1,138 @@
+package window
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+// Quantiles keeps the observations of the last n pushes, or of the last d duration, and answers
+// quantile queries like p50 or p99 over them.
+//
+// Observations are kept both in arrival order, in a ring, and in sorted order, so that a push
+// costs a binary search plus a shift of the sorted slice, and a query is O(1).
+type Quantiles[T cmp.Ordered] struct {
+	mu     sync.Mutex
+	n      int
+	d      time.Duration
+	ring   []observation[T] // Observations in arrival order, starting at start
+	start  int
+	len    int
+	sorted []T
+	clock  clock.Clock
+}
+
+// Option configures a Quantiles.
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+}
+
+// WithClock makes Quantiles timestamp observations with c instead of the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+type observation[T any] struct {
+	value T
+	at    time.Time
+}
+
+// NewQuantiles creates a Quantiles over the last n observations, dropping observations
+// older than d as well unless d is zero.
+func NewQuantiles[T cmp.Ordered](n int, d time.Duration, opts ...Option) *Quantiles[T] {
+	if n <= 0 {
+		return nil
+	}
+	cfg := config{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Quantiles[T]{
+		n:      n,
+		d:      d,
+		ring:   make([]observation[T], n),
+		sorted: make([]T, 0, n),
+		clock:  cfg.clock,
+	}
+}
+
+// Push records the observation v.
+func (q *Quantiles[T]) Push(v T) {
+	q.PushAt(v, q.clock.Now())
+}
+
+// PushAt records the observation v made at t. Observations are expected in time order.
+func (q *Quantiles[T]) PushAt(v T, t time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.expire(t)
+	if q.len == q.n {
+		q.drop()
+	}
+
+	q.ring[(q.start+q.len)%q.n] = observation[T]{value: v, at: t}
+	q.len++
+	i, _ := slices.BinarySearch(q.sorted, v)
+	q.sorted = slices.Insert(q.sorted, i, v)
+}
+
+// Must be called with mu held.
+func (q *Quantiles[T]) expire(now time.Time) {
+	if q.d <= 0 {
+		return
+	}
+	cutoff := now.Add(-q.d)
+	for q.len > 0 && q.ring[q.start].at.Before(cutoff) {
+		q.drop()
+	}
+}
+
+// Remove the oldest observation. Must be called with mu held.
+func (q *Quantiles[T]) drop() {
+	v := q.ring[q.start].value
+	q.start = (q.start + 1) % q.n
+	q.len--
+	i, _ := slices.BinarySearch(q.sorted, v)
+	q.sorted = slices.Delete(q.sorted, i, i+1)
+}
+
+// Len returns the number of observations in the window.
+func (q *Quantiles[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.len
+}
+
+// Quantile returns the observation at quantile p, between 0 and 1, using the nearest-rank
+// method, so Quantile(0.99) is the p99. Observations older than the duration of the window are
+// dropped first. It reports false if the window is empty.
+func (q *Quantiles[T]) Quantile(p float64) (T, bool) {
+	return q.QuantileAt(p, q.clock.Now())
+}
+
+// QuantileAt is like Quantile, but first drops observations that are older than the duration
+// of the window at now. A zero now drops nothing.
+func (q *Quantiles[T]) QuantileAt(p float64, now time.Time) (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !now.IsZero() {
+		q.expire(now)
+	}
+	if q.len == 0 {
+		var zero T
+		return zero, false
+	}
+	p = min(max(p, 0), 1)
+	rank := int(math.Ceil(p*float64(q.len))) - 1
+	return q.sorted[max(rank, 0)], true
+}
@@ -0,0 +1,74 @@
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/denis-ismailaj/cirque/clock"
+)
+
+func TestQuantiles(t *testing.T) {
+	q := NewQuantiles[int](100, 0)
+
+	if _, ok := q.Quantile(0.5); ok {
+		t.Fatal("Expected no quantile on an empty window.")
+	}
+
+	// Push 1..200 in a scrambled order, so only 101..200 stay in the window.
+	for i := 0; i < 200; i++ {
+		q.Push((i*37)%200 + 1)
+	}
+	var pushed []int
+	for i := 100; i < 200; i++ {
+		pushed = append(pushed, (i*37)%200+1)
+	}
+
+	for _, c := range []struct {
+		p        float64
+		expected int
+	}{{0, 1}, {0.5, 50}, {0.9, 90}, {0.99, 99}, {1, 100}} {
+		v, _ := q.Quantile(c.p)
+		// Find the rank of v among the pushed values still in the window.
+		rank := 0
+		for _, x := range pushed {
+			if x <= v {
+				rank++
+			}
+		}
+		if rank != c.expected {
+			t.Fatalf("Expected p%v to have rank %d, got %d.", c.p*100, c.expected, rank)
+		}
+	}
+}
+
+func TestQuantilesDuration(t *testing.T) {
+	c := clock.NewFake(time.Unix(1000, 0))
+	q := NewQuantiles[time.Duration](100, 10*time.Second, WithClock(c))
+
+	q.Push(time.Second)
+	c.Advance(5 * time.Second)
+	q.Push(3 * time.Second)
+	c.Advance(7 * time.Second)
+	q.Push(2 * time.Second)
+
+	if v, _ := q.Quantile(0); v != 2*time.Second || q.Len() != 2 {
+		t.Fatalf("Expected the oldest observation to expire, got min %v over %d.", v, q.Len())
+	}
+
+	// Queries drop observations that aged out since the last push.
+	c.Advance(18 * time.Second)
+	if _, ok := q.Quantile(1); ok || q.Len() != 0 {
+		t.Fatal("Expected every observation to expire.")
+	}
+}
+
+func TestQuantilesAt(t *testing.T) {
+	q := NewQuantiles[time.Duration](100, 10*time.Second)
+	base := time.Unix(1000, 0)
+
+	q.PushAt(time.Second, base)
+	q.PushAt(3*time.Second, base.Add(5*time.Second))
+	if v, _ := q.QuantileAt(0, base.Add(12*time.Second)); v != 3*time.Second || q.Len() != 1 {
+		t.Fatalf("Expected the oldest observation to expire, got min %v over %d.", v, q.Len())
+	}
+}
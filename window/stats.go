@@ -0,0 +1,96 @@
+package window
+
+import (
+	"math"
+	"sync"
+)
+
+// Stats maintains the sum, mean and variance of the last n pushed values in O(1) per push.
+//
+// The mean and variance are updated with Welford's algorithm adapted to a sliding window,
+// which avoids the precision loss of keeping a running sum of squares.
+type Stats[T Number] struct {
+	mu     sync.Mutex
+	values []T
+	count  uint64 // Number of values pushed so far
+	sum    T
+	mean   float64
+	m2     float64 // Sum of squared differences from the mean
+}
+
+// NewStats creates a Stats over a window of the last n values.
+func NewStats[T Number](n int) *Stats[T] {
+	if n <= 0 {
+		return nil
+	}
+	return &Stats[T]{values: make([]T, n)}
+}
+
+// Push adds v to the window, evicting the oldest value if the window is full.
+func (s *Stats[T]) Push(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.count % uint64(len(s.values))
+	x := float64(v)
+
+	if s.count < uint64(len(s.values)) {
+		// Growing window: regular Welford update.
+		n := float64(s.count + 1)
+		delta := x - s.mean
+		s.mean += delta / n
+		s.m2 += delta * (x - s.mean)
+	} else {
+		// Full window: replace the oldest value with the new one.
+		old := float64(s.values[i])
+		n := float64(len(s.values))
+		prevMean := s.mean
+		s.mean += (x - old) / n
+		s.m2 += (x - old) * (x - s.mean + old - prevMean)
+		// Rounding may push it slightly below zero.
+		s.m2 = max(s.m2, 0)
+		s.sum -= s.values[i]
+	}
+
+	s.values[i] = v
+	s.sum += v
+	s.count++
+}
+
+// Len returns the number of values in the window.
+func (s *Stats[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int(min(s.count, uint64(len(s.values))))
+}
+
+// Sum returns the sum of the values in the window.
+func (s *Stats[T]) Sum() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sum
+}
+
+// Mean returns the mean of the values in the window, or 0 if it's empty.
+func (s *Stats[T]) Mean() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mean
+}
+
+// Variance returns the population variance of the values in the window, or 0 if it's empty.
+func (s *Stats[T]) Variance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := min(s.count, uint64(len(s.values)))
+	if n == 0 {
+		return 0
+	}
+	return s.m2 / float64(n)
+}
+
+// StdDev returns the population standard deviation of the values in the window.
+func (s *Stats[T]) StdDev() float64 {
+	return math.Sqrt(s.Variance())
+}
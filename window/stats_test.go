@@ -0,0 +1,44 @@
+package window
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	n := 10
+	s := NewStats[int](n)
+
+	if s.Mean() != 0 || s.Variance() != 0 {
+		t.Fatal("Expected zero statistics for an empty window.")
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	var pushed []int
+	for i := 0; i < 1000; i++ {
+		v := rng.Intn(1000)
+		s.Push(v)
+		pushed = append(pushed, v)
+
+		// Compare with a direct computation over the window.
+		last := pushed[max(0, len(pushed)-n):]
+		sum := 0
+		for _, x := range last {
+			sum += x
+		}
+		mean := float64(sum) / float64(len(last))
+		variance := 0.0
+		for _, x := range last {
+			variance += (float64(x) - mean) * (float64(x) - mean)
+		}
+		variance /= float64(len(last))
+
+		if s.Sum() != sum || s.Len() != len(last) {
+			t.Fatalf("Expected sum %d over %d values, got %d over %d.", sum, len(last), s.Sum(), s.Len())
+		}
+		if math.Abs(s.Mean()-mean) > 1e-9 || math.Abs(s.Variance()-variance) > 1e-6 {
+			t.Fatalf("Expected mean %v and variance %v, got %v and %v.", mean, variance, s.Mean(), s.Variance())
+		}
+	}
+}